@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/metal-toolbox/bioscfg/internal/bioscfg"
+	"github.com/spf13/cobra"
+)
+
+var cancelReason string
+
+// cancelCmd requests the cancellation of a running condition
+var cancelCmd = &cobra.Command{
+	Use:   "cancel CONDITION_ID",
+	Short: "Cancel a running BiosControl condition",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		err := bioscfg.Cancel(ConfigFile, LogLevel, args[0], cancelReason)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Println("cancellation requested for condition", args[0])
+	},
+}
+
+func init() {
+	cancelCmd.Flags().StringVar(&cancelReason, "reason", "", "reason reported in the condition status")
+	rootCmd.AddCommand(cancelCmd)
+}
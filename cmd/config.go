@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/bioscfg/internal/biosconfig"
+)
+
+var (
+	registryDir    string
+	registryFile   string
+	registryVendor string
+	registryModel  string
+)
+
+// configCmd groups the offline BIOS config file commands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Compare and validate BIOS config files, without a BMC",
+}
+
+// configDiffCmd compares two BIOS config files
+var configDiffCmd = &cobra.Command{
+	Use:   "diff FILE_A FILE_B",
+	Short: "Show the BIOS attributes changed from FILE_A to FILE_B, exits with 1 when they differ",
+	Args:  cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		a, _, err := biosconfig.ParseFile(args[0])
+		if err != nil {
+			exitWithError(err)
+		}
+
+		b, _, err := biosconfig.ParseFile(args[1])
+		if err != nil {
+			exitWithError(err)
+		}
+
+		changes := biosconfig.Diff(a, b)
+		for i := range changes {
+			fmt.Println(changes[i].String())
+		}
+
+		if len(changes) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// configValidateCmd validates a BIOS config file against the attribute registry of the server model
+var configValidateCmd = &cobra.Command{
+	Use:   "validate FILE",
+	Short: "Validate a BIOS config file against a recorded attribute registry, exits with 1 when not valid",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		attrs, format, err := biosconfig.ParseFile(args[0])
+		if err != nil {
+			exitWithError(err)
+		}
+
+		var registry *biosconfig.Registry
+		if registryFile != "" {
+			registry, err = biosconfig.LoadRegistryFile(registryFile)
+		} else {
+			if registryVendor == "" {
+				exitWithError(fmt.Errorf("one of --vendor or --registry is required"))
+			}
+
+			registry, err = biosconfig.LoadRegistry(registryDir, registryVendor, registryModel)
+		}
+
+		if err != nil {
+			exitWithError(err)
+		}
+
+		// the attributes of the Dell components other than the BIOS are not in the BIOS registry
+		bios, components := attrs.SplitComponents(format)
+		if len(components) > 0 {
+			fmt.Printf("%d attributes of other components not validated: %s\n",
+				len(components), strings.Join(components.Components(), ", "))
+		}
+
+		problems := registry.Validate(bios)
+		for i := range problems {
+			fmt.Println(problems[i].String())
+		}
+
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+
+		fmt.Printf("%d attributes valid (%s)\n", len(bios), format)
+	},
+}
+
+func init() {
+	configValidateCmd.Flags().StringVar(&registryVendor, "vendor", "", "server vendor - dell, supermicro")
+	configValidateCmd.Flags().StringVar(&registryModel, "model", "", "server model, the vendor default registry is used when not recorded")
+	configValidateCmd.Flags().StringVar(&registryDir, "registry-dir", "registries", "directory of the registries recorded as <vendor>/<model>.json or .xml")
+	configValidateCmd.Flags().StringVar(&registryFile, "registry", "", "registry file, instead of the --vendor and --model lookup")
+
+	configCmd.AddCommand(configDiffCmd, configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/bioscfg/internal/bioscfg"
+)
+
+var (
+	localTarget       bioscfg.LocalTarget
+	localClearPending bool
+	applyConfigFile   string
+	applyConfigURL    string
+)
+
+// applyCmd sets the BIOS config of a server without NATS
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a BIOS config file to a server, without NATS",
+	Run: func(cmd *cobra.Command, _ []string) {
+		if (applyConfigFile == "") == (applyConfigURL == "") {
+			exitWithError(fmt.Errorf("one of --file or --url is required"))
+		}
+
+		configURL, err := url.Parse(applyConfigURL)
+		if applyConfigFile != "" {
+			var path string
+			path, err = filepath.Abs(applyConfigFile)
+			configURL = &url.URL{Scheme: "file", Path: path}
+		}
+
+		if err != nil {
+			exitWithError(err)
+		}
+
+		params := &bioscfg.TaskParameters{ClearPending: localClearPending}
+		params.Action = rctypes.SetConfig
+		params.BiosConfigURL = configURL
+
+		runLocal(cmd.Context(), params)
+	},
+}
+
+// resetCmd resets the BIOS config of a server to the defaults without NATS
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Reset the BIOS config of a server to the defaults, without NATS",
+	Run: func(cmd *cobra.Command, _ []string) {
+		params := &bioscfg.TaskParameters{ClearPending: localClearPending}
+		params.Action = rctypes.ResetConfig
+
+		runLocal(cmd.Context(), params)
+	},
+}
+
+// getCmd reports the BIOS config of a server without NATS
+var getCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get the current BIOS config of a server, without NATS",
+	Run: func(cmd *cobra.Command, _ []string) {
+		params := &bioscfg.TaskParameters{}
+		params.Action = bioscfg.GetConfig
+
+		runLocal(cmd.Context(), params)
+	},
+}
+
+func runLocal(ctx context.Context, params *bioscfg.TaskParameters) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := bioscfg.RunLocal(ctx, ConfigFile, LogLevel, &localTarget, params, os.Stdout); err != nil {
+		stop()
+		exitWithError(err)
+	}
+}
+
+func exitWithError(err error) {
+	fmt.Println(err)
+	os.Exit(1)
+}
+
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&localTarget.ServerID, "server", "", "server ID, the server is looked up in fleetdb unless --bmc-addr is set")
+	cmd.Flags().StringVar(&localTarget.BMCAddress, "bmc-addr", "", "BMC address, instead of the fleetdb lookup")
+	cmd.Flags().StringVar(&localTarget.BMCUser, "user", "", "BMC user, with --bmc-addr")
+	cmd.Flags().StringVar(&localTarget.BMCPassFile, "pass-file", "", "file holding the BMC password, with --bmc-addr")
+	cmd.Flags().StringVar(&localTarget.Vendor, "vendor", "", "server vendor, with --bmc-addr - dell, supermicro")
+	cmd.Flags().StringVar(&localTarget.Model, "model", "", "server model, with --bmc-addr")
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{applyCmd, resetCmd, getCmd} {
+		addTargetFlags(cmd)
+		rootCmd.AddCommand(cmd)
+	}
+
+	applyCmd.Flags().StringVar(&applyConfigFile, "file", "", "BIOS config file to apply")
+	applyCmd.Flags().StringVar(&applyConfigURL, "url", "", "URL of the BIOS config file to apply")
+
+	for _, cmd := range []*cobra.Command{applyCmd, resetCmd} {
+		cmd.Flags().BoolVar(&localClearPending, "clear-pending", false, "discard the BIOS settings staged on the BMC first")
+	}
+}
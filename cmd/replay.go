@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/metal-toolbox/bioscfg/internal/bioscfg"
+)
+
+var (
+	replayTarget bioscfg.LocalTarget
+	replayDryrun bool
+)
+
+// replayCmd runs a condition task from a JSON file
+var replayCmd = &cobra.Command{
+	Use:   "replay CONDITION_JSON",
+	Short: "Replay a BiosControl condition task from a JSON file, without NATS",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := bioscfg.Replay(ctx, ConfigFile, LogLevel, args[0], &replayTarget, replayDryrun, os.Stdout)
+		if err != nil {
+			stop()
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	replayCmd.Flags().BoolVar(&replayDryrun, "dryrun", false, "run against the simulated BMC")
+	replayCmd.Flags().StringVar(&replayTarget.ServerID, "server", "", "server ID, must match the condition asset_id")
+	replayCmd.Flags().StringVar(&replayTarget.BMCAddress, "bmc-addr", "", "BMC address, instead of the fleetdb lookup")
+	replayCmd.Flags().StringVar(&replayTarget.BMCUser, "user", "", "BMC user, with --bmc-addr")
+	replayCmd.Flags().StringVar(&replayTarget.BMCPassFile, "pass-file", "", "file holding the BMC password, with --bmc-addr")
+	replayCmd.Flags().StringVar(&replayTarget.Vendor, "vendor", "", "server vendor, with --bmc-addr - dell, supermicro")
+	replayCmd.Flags().StringVar(&replayTarget.Model, "model", "", "server model, with --bmc-addr")
+
+	rootCmd.AddCommand(replayCmd)
+}
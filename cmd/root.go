@@ -52,5 +52,5 @@ func init() {
 		StringVar(&LogLevel, "log-level", "info", "set logging level - debug, trace")
 
 	rootCmd.PersistentFlags().
-		BoolVarP(&EnableProfiling, "enable-pprof", "", false, "Enable profiling endpoint at: http://localhost:9091")
+		BoolVarP(&EnableProfiling, "enable-pprof", "", false, "Enable profiling endpoint, at listen.profiling (default: http://localhost:9091)")
 }
@@ -4,8 +4,13 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
 
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
 
 	"github.com/metal-toolbox/bioscfg/internal/model"
 )
@@ -17,6 +22,22 @@ func (th *TaskHandler) handleAction(ctx context.Context) error {
 		return th.resetBiosConfig(ctx)
 	case rctypes.SetConfig:
 		return th.setBiosConfig(ctx)
+	case GetConfig:
+		return th.getBiosConfig(ctx)
+	case PendingConfig:
+		return th.pendingBiosConfig(ctx)
+	case SecureBootStatus:
+		return th.secureBootStatus(ctx)
+	case SecureBootEnable:
+		return th.setSecureBoot(ctx, true)
+	case SecureBootDisable:
+		return th.setSecureBoot(ctx, false)
+	case SecureBootResetKeys:
+		return th.resetSecureBootKeys(ctx)
+	case SetBiosPassword:
+		return th.setBiosPassword(ctx)
+	case SetBootMode:
+		return th.setBootMode(ctx)
 	default:
 		return th.failedWithError(ctx, string(th.task.Parameters.Action), errUnsupportedAction)
 	}
@@ -35,8 +56,19 @@ func (th *TaskHandler) resetBiosConfig(ctx context.Context) error {
 		return err
 	}
 
+	if th.task.Parameters.ClearPending {
+		if err := th.clearPendingBiosConfig(ctx); err != nil {
+			return err
+		}
+	}
+
+	knownJobs, err := th.biosJobIDs(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
 	// Reset Bios
-	err = th.bmcClient.ResetBiosConfig(ctx)
+	err = th.write(ctx, "bios settings reset", th.bmcClient.ResetBiosConfig)
 	if err != nil {
 		return th.failedWithError(ctx, "error reseting bios", err)
 	}
@@ -46,14 +78,27 @@ func (th *TaskHandler) resetBiosConfig(ctx context.Context) error {
 		return err
 	}
 
+	job, err := th.createdBiosJob(ctx, knownJobs)
+	if err != nil {
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
 	// Reboot (if ON)
 	if state == model.PowerStateOn {
-		err = th.bmcClient.SetPowerState(ctx, model.PowerStateReset)
+		err = th.reboot(ctx, model.PowerActionReset, job)
 		if err != nil {
 			return th.failedWithError(ctx, "failed to reboot server", err)
 		}
 
-		return th.successful(ctx, "rebooting server")
+		if job != nil {
+			return th.trackBiosJob(ctx, job, true)
+		}
+
+		return th.successful(ctx, "server rebooted")
+	}
+
+	if job != nil {
+		return th.trackBiosJob(ctx, job, false)
 	}
 
 	return th.successful(ctx, "skipping server reboot, not on")
@@ -61,46 +106,164 @@ func (th *TaskHandler) resetBiosConfig(ctx context.Context) error {
 
 // setBiosConfig sets BIOS Config
 func (th *TaskHandler) setBiosConfig(ctx context.Context) error {
-	var configURL = ""
-	if th.task.Parameters.BiosConfigURL != nil {
-		configURL = th.task.Parameters.BiosConfigURL.String()
+	configURL := th.task.Parameters.BiosConfigURL
+	if configURL == nil || configURL.String() == "" {
+		return th.failed(ctx, "no Bios Config URL was found")
 	}
 
-	if configURL == "" {
-		return th.failed(ctx, "no Bios Config URL was found")
+	body, err := th.fetchBiosConfig(ctx, configURL)
+	if err != nil {
+		return th.failedWithError(ctx, "failed to get bios config from url", err)
+	}
+
+	err = th.publishActive(ctx, "got bios config from url")
+	if err != nil {
+		return err
+	}
+
+	if th.task.Parameters.ClearPending {
+		if err := th.clearPendingBiosConfig(ctx); err != nil {
+			return err
+		}
+	}
+
+	knownJobs, err := th.biosJobIDs(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
+	err = th.write(ctx, "bios config set", func(ctx context.Context) error {
+		return th.bmcClient.SetBiosConfigFromFile(ctx, string(body))
+	})
+	if err != nil {
+		return th.failedWithError(ctx, "failed to set bios config through the bmc", err)
 	}
 
-	req, err := http.NewRequest(http.MethodGet, configURL, http.NoBody)
+	job, err := th.createdBiosJob(ctx, knownJobs)
 	if err != nil {
-		return th.failedWithError(ctx, "failed to create http request", err)
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
+	if job != nil {
+		return th.trackBiosJob(ctx, job, false)
+	}
+
+	return th.successful(ctx, "bios set")
+}
+
+// fetchBiosConfig downloads the BIOS config file, within the fetch_config step deadline.
+func (th *TaskHandler) fetchBiosConfig(ctx context.Context, configURL *url.URL) ([]byte, error) {
+	ctx, cancel := th.stepDeadline(ctx, stepFetchConfig)
+	defer cancel()
+
+	if th.local && configURL.Scheme == "file" {
+		return readLocalFile(ctx, configURL.Path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL.String(), http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create http request")
 	}
-	req = req.WithContext(ctx)
 
 	client := http.DefaultClient
 	resp, err := client.Do(req)
 	if err != nil {
-		return th.failedWithError(ctx, "failed to get bios config from url", err)
+		return nil, stepError(ctx, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return th.failed(ctx, resp.Status)
+		return nil, errors.New(resp.Status)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return th.failedWithError(ctx, "failed to read file from response body", err)
+		return nil, stepError(ctx, errors.Wrap(err, "failed to read file from response body"))
 	}
 
-	err = th.publishActive(ctx, "got bios config from url")
+	return body, nil
+}
+
+// readLocalFile reads the file until the context is done, a read blocked on a network file system is left behind.
+func readLocalFile(ctx context.Context, path string) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+
+	if ctx.Err() != nil {
+		return nil, errors.Wrap(context.Cause(ctx), "reading "+path)
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		data, err := os.ReadFile(path)
+		done <- result{data, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, errors.Wrap(context.Cause(ctx), "reading "+path)
+	}
+}
+
+// getBiosConfig reports the current BIOS settings
+func (th *TaskHandler) getBiosConfig(ctx context.Context) error {
+	settings, err := th.bmcClient.BiosSettings(ctx)
 	if err != nil {
-		return err
+		return th.failedWithError(ctx, "error getting bios settings", err)
 	}
 
-	err = th.bmcClient.SetBiosConfigFromFile(ctx, string(body))
+	return th.successful(ctx, "bios settings: "+formatSettings(settings))
+}
+
+// pendingBiosConfig reports the BIOS settings staged on the BMC
+func (th *TaskHandler) pendingBiosConfig(ctx context.Context) error {
+	pending, err := th.bmcClient.PendingBiosSettings(ctx)
 	if err != nil {
-		return th.failedWithError(ctx, "failed to set bios config through the bmc", err)
+		return th.failedWithError(ctx, "error getting pending bios settings", err)
 	}
 
-	return th.successful(ctx, "bios set")
+	if len(pending) == 0 {
+		return th.successful(ctx, "no pending bios settings")
+	}
+
+	return th.successful(ctx, "pending bios settings: "+formatSettings(pending))
+}
+
+// clearPendingBiosConfig discards the BIOS settings staged on the BMC, so they are not applied along with the action
+func (th *TaskHandler) clearPendingBiosConfig(ctx context.Context) error {
+	pending, err := th.bmcClient.PendingBiosSettings(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error getting pending bios settings", err)
+	}
+
+	if len(pending) == 0 {
+		return th.publishActive(ctx, "no pending bios settings to clear")
+	}
+
+	err = th.write(ctx, "pending bios settings cleared", th.bmcClient.ClearPendingBiosSettings)
+	if err != nil {
+		return th.failedWithError(ctx, "error clearing pending bios settings", err)
+	}
+
+	return th.publishActive(ctx, "cleared pending bios settings: "+formatSettings(pending))
+}
+
+func formatSettings(settings map[string]string) string {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+settings[name])
+	}
+
+	return strings.Join(pairs, ", ")
 }
@@ -0,0 +1,37 @@
+package bioscfg
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchBiosConfigLocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bios config", "r6515 #1.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(`{"BootMode": "Uefi"}`), 0o600))
+
+	configURL, err := url.Parse((&url.URL{Scheme: "file", Path: path}).String())
+	require.NoError(t, err)
+
+	th := &TaskHandler{local: true}
+
+	body, err := th.fetchBiosConfig(context.Background(), configURL)
+	require.NoError(t, err)
+	assert.Equal(t, `{"BootMode": "Uefi"}`, string(body))
+
+	// the file is not read once the deadline is exceeded
+	ctx, cancel := withDeadline(context.Background(), stepTask, time.Nanosecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	_, err = th.fetchBiosConfig(ctx, configURL)
+	assert.ErrorIs(t, err, errDeadlineExceeded)
+}
@@ -2,14 +2,20 @@ package bioscfg
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 
 	"github.com/metal-toolbox/ctrl"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/metal-toolbox/bioscfg/internal/capabilities"
 	"github.com/metal-toolbox/bioscfg/internal/config"
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
 	"github.com/metal-toolbox/bioscfg/internal/store/fleetdb"
+	"github.com/metal-toolbox/bioscfg/internal/store/kv"
 )
 
 var (
@@ -18,20 +24,43 @@ var (
 
 // BiosCfg BiosCfg Controller Struct
 type BiosCfg struct {
-	cfg     *config.Configuration
-	logger  *logrus.Entry
-	fleetdb *fleetdb.Store
-	nc      *ctrl.NatsController
+	cfg    *config.Configuration
+	logger *logrus.Entry
+	// fleetdb is the store of the facilities without their own fleetdb endpoint
+	fleetdb      *fleetdb.Store
+	facilities   []*facility
+	capabilities *capabilities.Matrix
+	shutdown     *shutdown
+	// bmcResets is set when the BMC recovery is enabled
+	bmcResets bmc.ResetLimiter
+	// natsConn is shared by the KV stores and the health checks,
+	// the controller connection is not exposed by the controller library, it is not set in the orchestrator mode.
+	natsConn *nats.Conn
+	// cancellations are the condition cancellation requests
+	cancellations *kv.Cancellations
+	// listening is set while the controller listens for conditions
+	listening atomic.Bool
 }
 
 // New create a new BiosCfg Controller
 func New(ctx context.Context, cfg *config.Configuration, logger *logrus.Entry) (*BiosCfg, error) {
+	matrix, err := capabilities.New(cfg.Capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateDeadlines(&cfg.Deadlines); err != nil {
+		return nil, err
+	}
+
 	bc := &BiosCfg{
-		cfg:    cfg,
-		logger: logger,
+		cfg:          cfg,
+		logger:       logger,
+		capabilities: matrix,
+		shutdown:     newShutdown(),
 	}
 
-	err := bc.initDependences(ctx)
+	err = bc.initDependences(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -39,55 +68,175 @@ func New(ctx context.Context, cfg *config.Configuration, logger *logrus.Entry) (
 	return bc, nil
 }
 
-// Listen listen to Nats for tasks
+// facility is a facility conditions are received for, with its own NATS subscription and concurrency,
+// the BMC session management, capabilities and shutdown are shared by the facilities.
+type facility struct {
+	code        string
+	concurrency int
+	nc          *ctrl.NatsController
+	fleetdb     *fleetdb.Store
+	// fleetdbOverride is set when the facility has its own fleetdb endpoint
+	fleetdbOverride bool
+}
+
+// Listen listen to Nats for tasks of each facility, or fetches them from the orchestrator API in the orchestrator mode,
+// until the context is cancelled, new conditions are then no longer accepted, and the in-flight conditions are drained.
 func (bc *BiosCfg) Listen(ctx context.Context) error {
-	handleFactory := func() ctrl.TaskHandler {
-		return &TaskHandler{
-			cfg:          bc.cfg,
-			logger:       bc.logger,
-			controllerID: bc.nc.ID(),
-			fleetdb:      bc.fleetdb,
-		}
+	if bc.cfg.Mode == config.ModeOrchestrator {
+		return bc.listenOrchestrator(ctx)
 	}
 
-	err := bc.nc.ListenEvents(ctx, handleFactory)
-	if err != nil {
-		return err
+	defer bc.natsConn.Close()
+
+	// a facility listener failing stops the others, the in-flight conditions of all facilities are drained
+	listenCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var wg sync.WaitGroup
+	for _, f := range bc.facilities {
+		wg.Add(1)
+
+		go func(f *facility) {
+			defer wg.Done()
+
+			handleFactory := func() ctrl.TaskHandler {
+				return bc.newTaskHandler(f, f.nc.ID())
+			}
+
+			if err := f.nc.ListenEvents(listenCtx, handleFactory); err != nil && listenCtx.Err() == nil {
+				cancel(errors.Wrap(err, "facility "+f.code))
+			}
+		}(f)
 	}
 
-	return nil
+	bc.listening.Store(true)
+	wg.Wait()
+	bc.listening.Store(false)
+
+	// the listeners return once the context is cancelled, without waiting for the in-flight conditions
+	var err error
+	if ctx.Err() == nil {
+		err = context.Cause(listenCtx)
+		bc.logger.WithError(err).WithField("grace_period", bc.cfg.ShutdownGracePeriod.String()).
+			Error("facility listener failed, draining in-flight conditions")
+	} else {
+		bc.logger.WithField("grace_period", bc.cfg.ShutdownGracePeriod.String()).Info("shutting down, draining in-flight conditions")
+	}
+
+	bc.shutdown.drain(bc.cfg.ShutdownGracePeriod, bc.logger)
+
+	return err
+}
+
+func (bc *BiosCfg) newTaskHandler(f *facility, controllerID string) *TaskHandler {
+	th := &TaskHandler{
+		cfg:          bc.cfg,
+		logger:       bc.logger.WithField("facility", f.code),
+		controllerID: controllerID,
+		fleetdb:      f.fleetdb,
+		bmcResets:    bc.bmcResets,
+		capabilities: bc.capabilities,
+		shutdown:     bc.shutdown,
+		facility:     f.code,
+	}
+
+	// not watched in the orchestrator mode, without NATS
+	if bc.cancellations != nil {
+		th.cancellations = bc.cancellations
+	}
+
+	return th
 }
 
 // initDependences Initialize network dependencies
 func (bc *BiosCfg) initDependences(ctx context.Context) error {
-	err := bc.initNats(ctx)
+	err := bc.initFacilities(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the conditions are not cancellable, and the BMC recovery is not available, without NATS
+	if bc.cfg.Mode == config.ModeOrchestrator {
+		return nil
+	}
+
+	err = bc.initNats(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to initialize connection to nats")
 	}
 
-	err = bc.initFleetDB(ctx)
+	bc.cancellations, err = kv.NewCancellations(bc.natsConn, bc.cfg.Endpoints.Nats.KVReplicationFactor)
 	if err != nil {
-		return errors.Wrap(err, "failed to initialize connection to fleetdb")
+		return errors.Wrap(err, "failed to initialize cancellations kv")
+	}
+
+	if bc.cfg.BMC.Recovery.Enabled {
+		resets, err := kv.NewBMCResets(bc.natsConn, bc.cfg.Endpoints.Nats.KVReplicationFactor)
+		if err != nil {
+			return errors.Wrap(err, "failed to initialize bmc resets kv")
+		}
+
+		bc.bmcResets = resets
+	}
+
+	return nil
+}
+
+// initFacilities initializes the fleetdb store of each facility, the default store is shared by the facilities
+// without their own fleetdb endpoint.
+func (bc *BiosCfg) initFacilities(ctx context.Context) error {
+	for i := range bc.cfg.Facilities {
+		fcfg := &bc.cfg.Facilities[i]
+		f := &facility{code: fcfg.Code, concurrency: fcfg.Concurrency}
+
+		var err error
+		switch {
+		case fcfg.FleetDB != nil:
+			f.fleetdb, err = bc.newFleetDB(ctx, fcfg.FleetDB)
+			f.fleetdbOverride = true
+		case bc.fleetdb == nil:
+			bc.fleetdb, err = bc.newFleetDB(ctx, &bc.cfg.Endpoints.FleetDB)
+			f.fleetdb = bc.fleetdb
+		default:
+			f.fleetdb = bc.fleetdb
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "failed to initialize connection to fleetdb of facility "+f.code)
+		}
+
+		bc.facilities = append(bc.facilities, f)
 	}
 
 	return nil
 }
 
+// initNats connects the NATS controller of each facility, and the connection shared by the KV stores.
 func (bc *BiosCfg) initNats(ctx context.Context) error {
-	bc.nc = ctrl.NewNatsController(
-		string(rctypes.BiosControl),
-		bc.cfg.FacilityCode,
-		string(rctypes.BiosControl),
-		bc.cfg.Endpoints.Nats.URL,
-		bc.cfg.Endpoints.Nats.CredsFile,
-		rctypes.BiosControl,
-		ctrl.WithConcurrency(bc.cfg.Concurrency),
-		ctrl.WithKVReplicas(bc.cfg.Endpoints.Nats.KVReplicationFactor),
-		ctrl.WithLogger(bc.logger.Logger),
-		ctrl.WithConnectionTimeout(bc.cfg.Endpoints.Nats.ConnectTimeout),
-	)
+	for _, f := range bc.facilities {
+		f.nc = ctrl.NewNatsController(
+			string(rctypes.BiosControl),
+			f.code,
+			string(rctypes.BiosControl),
+			bc.cfg.Endpoints.Nats.URL,
+			bc.cfg.Endpoints.Nats.CredsFile,
+			rctypes.BiosControl,
+			ctrl.WithConcurrency(f.concurrency),
+			ctrl.WithKVReplicas(bc.cfg.Endpoints.Nats.KVReplicationFactor),
+			ctrl.WithLogger(bc.logger.Logger),
+			ctrl.WithConnectionTimeout(bc.cfg.Endpoints.Nats.ConnectTimeout),
+		)
+
+		err := f.nc.Connect(ctx)
+		if err != nil {
+			bc.logger.WithField("facility", f.code).Error(err)
+			return errors.Wrap(err, "facility "+f.code)
+		}
+	}
 
-	err := bc.nc.Connect(ctx)
+	var err error
+
+	bc.natsConn, err = kv.Connect(&bc.cfg.Endpoints.Nats)
 	if err != nil {
 		bc.logger.Error(err)
 		return err
@@ -96,17 +245,10 @@ func (bc *BiosCfg) initNats(ctx context.Context) error {
 	return nil
 }
 
-func (bc *BiosCfg) initFleetDB(ctx context.Context) error {
-	store, err := fleetdb.New(
+func (bc *BiosCfg) newFleetDB(ctx context.Context, cfg *fleetdb.Config) (*fleetdb.Store, error) {
+	return fleetdb.New(
 		ctx,
-		&bc.cfg.Endpoints.FleetDB,
+		cfg,
 		bc.logger.Logger,
 	)
-	if err != nil {
-		return err
-	}
-
-	bc.fleetdb = store
-
-	return nil
 }
@@ -0,0 +1,118 @@
+package bioscfg
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+)
+
+// boot disk partition table layouts, UEFI boots from a GPT disk and Legacy BIOS from an MBR disk
+const (
+	bootDiskLayoutGPT = "gpt"
+	bootDiskLayoutMBR = "mbr"
+)
+
+var bootModeDiskLayout = map[bmc.BootMode]string{
+	bmc.BootModeUEFI:   bootDiskLayoutGPT,
+	bmc.BootModeLegacy: bootDiskLayoutMBR,
+}
+
+// setBootMode converts the server firmware boot mode, and verifies the new mode after a reboot
+func (th *TaskHandler) setBootMode(ctx context.Context) error {
+	mode := bmc.ParseBootMode(th.task.Parameters.BootMode)
+	if mode == bmc.BootModeUnknown {
+		return th.failedWithError(ctx, "boot_mode parameter expected: uefi or legacy", errInvalidConditionParams)
+	}
+
+	current, err := th.bmcClient.GetBootMode(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error getting boot mode", err)
+	}
+
+	err = th.publishActive(ctx, "current boot mode: "+string(current))
+	if err != nil {
+		return err
+	}
+
+	if current == mode {
+		return th.successful(ctx, "boot mode already "+string(mode))
+	}
+
+	if err := th.checkBootDiskLayout(mode); err != nil {
+		return th.failedWithError(ctx, "refusing to change boot mode", err)
+	}
+
+	knownJobs, err := th.biosJobIDs(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
+	err = th.write(ctx, "boot mode set to "+string(mode), func(ctx context.Context) error {
+		return th.bmcClient.SetBootMode(ctx, mode)
+	})
+	if err != nil {
+		return th.failedWithError(ctx, "error setting boot mode", err)
+	}
+
+	err = th.publishActive(ctx, "boot mode set to "+string(mode)+", applied on next boot")
+	if err != nil {
+		return err
+	}
+
+	job, err := th.createdBiosJob(ctx, knownJobs)
+	if err != nil {
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
+	rebooted, err := th.rebootIfOn(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "failed to reboot server", err)
+	}
+
+	if !rebooted {
+		return th.successful(ctx, "skipping server reboot, not on, boot mode "+string(mode)+" on next boot")
+	}
+
+	if job != nil {
+		job, err = th.awaitBiosJob(ctx, job, rebooted)
+		if err != nil {
+			return th.biosJobFailed(ctx, job, err)
+		}
+	}
+
+	err = th.waitVerified(ctx, "boot mode "+string(mode), func(ctx context.Context) (bool, error) {
+		current, err = th.bmcClient.GetBootMode(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		return current == mode, nil
+	})
+	if err != nil {
+		return th.failedWithError(ctx, "boot mode not verified", err)
+	}
+
+	return th.successful(ctx, "boot mode verified: "+string(current))
+}
+
+// checkBootDiskLayout verifies the boot disk layout is compatible with the boot mode,
+// an unknown or incompatible layout is accepted only when forced.
+func (th *TaskHandler) checkBootDiskLayout(mode bmc.BootMode) error {
+	if th.task.Parameters.Force {
+		return nil
+	}
+
+	layout := strings.ToLower(th.task.Parameters.BootDiskLayout)
+
+	switch layout {
+	case "":
+		return errors.Wrap(errOverrideRequired, "boot disk layout unknown, set boot_disk_layout or force")
+	case bootModeDiskLayout[mode]:
+		return nil
+	default:
+		return errors.Wrap(errOverrideRequired, "boot disk layout "+layout+" does not boot in "+string(mode)+" mode")
+	}
+}
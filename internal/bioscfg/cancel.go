@@ -0,0 +1,115 @@
+package bioscfg
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/config"
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+	"github.com/metal-toolbox/bioscfg/internal/store/kv"
+)
+
+// cancellationWatcher notifies the cancellation requests of a condition.
+type cancellationWatcher interface {
+	Watch(ctx context.Context, conditionID string, cancel func(reason string)) error
+}
+
+// cancelError is the cause of a handler context cancelled on an operator request.
+type cancelError struct {
+	reason string
+}
+
+func (e *cancelError) Error() string {
+	if e.reason == "" {
+		return errCancelled.Error()
+	}
+
+	return errCancelled.Error() + ": " + e.reason
+}
+
+func (e *cancelError) Is(target error) bool {
+	return target == errCancelled
+}
+
+// watchCancellation returns the handler context, cancelled once the cancellation of the condition is requested,
+// no further change is then started on the BMC, a change already started is completed.
+func (th *TaskHandler) watchCancellation(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if th.cancellations == nil {
+		return ctx, func() { cancel(nil) }
+	}
+
+	err := th.cancellations.Watch(ctx, th.task.ID.String(), func(reason string) {
+		th.logger.WithField("reason", reason).Warn("condition cancellation requested")
+		metrics.ConditionCancelled(string(th.task.Parameters.Action), th.facility)
+
+		cancel(&cancelError{reason: reason})
+	})
+	if err != nil {
+		th.logger.WithError(err).Warn("condition cancellation requests are not watched")
+	}
+
+	return ctx, func() { cancel(nil) }
+}
+
+// stopCause returns the cause of a handler context cancelled by a deadline, or a cancellation request.
+func stopCause(ctx context.Context) error {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, errDeadlineExceeded) || errors.Is(cause, errCancelled) {
+		return cause
+	}
+
+	return nil
+}
+
+// appliedStatus lists the changes made on the BMC.
+func (th *TaskHandler) appliedStatus() string {
+	if len(th.applied) == 0 {
+		return "no change applied"
+	}
+
+	return "applied: " + strings.Join(th.applied, ", ")
+}
+
+// reportStopped publishes the failed status of a condition which exceeded a deadline, or was cancelled,
+// when the handler returned without publishing a final status.
+func (th *TaskHandler) reportStopped(ctx context.Context) {
+	cause := stopCause(ctx)
+	if cause == nil || th.task == nil || rctypes.StateIsComplete(th.task.State) {
+		return
+	}
+
+	if errPublish := th.failed(ctx, "condition stopped"); errPublish != nil {
+		th.logger.WithError(errPublish).Error("failed to publish stopped condition status")
+	}
+}
+
+// Cancel requests the cancellation of a condition, the controller running it stops it,
+// a condition not yet picked up is stopped once picked up within a day of the request.
+func Cancel(configFile, logLevel, conditionID, reason string) error {
+	if _, err := uuid.Parse(conditionID); err != nil {
+		return errors.Wrap(errInvalidConditionParams, "condition id: "+err.Error())
+	}
+
+	cfg, err := config.Load(configFile, logLevel)
+	if err != nil {
+		return err
+	}
+
+	conn, err := kv.Connect(&cfg.Endpoints.Nats)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	cancellations, err := kv.NewCancellations(conn, cfg.Endpoints.Nats.KVReplicationFactor)
+	if err != nil {
+		return err
+	}
+
+	return cancellations.Request(conditionID, reason)
+}
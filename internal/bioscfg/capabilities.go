@@ -0,0 +1,48 @@
+package bioscfg
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/capabilities"
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+)
+
+// checkCapabilities fails actions the capability matrix declares unsupported by the server.
+//
+// The check is run before the BMC is contacted, unless the matrix matches the server on firmware versions,
+// in which case it runs in the pre-flight checks, with the versions read from the BMC.
+func (th *TaskHandler) checkCapabilities(ctx context.Context, withVersions bool) error {
+	if th.capabilities == nil {
+		return nil
+	}
+
+	target := &capabilities.Target{
+		Vendor: th.server.Vendor,
+		Model:  th.server.Model,
+	}
+
+	if th.capabilities.NeedsVersions(target) != withVersions {
+		return nil
+	}
+
+	if withVersions {
+		versions, err := th.bmcClient.FirmwareVersions(ctx)
+		if err != nil {
+			return errors.Wrap(errPreflight, "error getting firmware versions: "+err.Error())
+		}
+
+		target.BIOSVersion = versions.BIOS
+		target.BMCVersion = versions.BMC
+	}
+
+	action := string(th.task.Parameters.Action)
+
+	err := th.capabilities.Check(target, action)
+	if errors.Is(err, capabilities.ErrUnsupported) {
+		metrics.UnsupportedAction(th.server.Vendor, th.server.Model, action, th.facility)
+	}
+
+	return err
+}
@@ -0,0 +1,64 @@
+package bioscfg
+
+import (
+	"context"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+)
+
+// FaultCredentialsInvalid is the task Fault FailAt value set when the BMC rejects the fleetdb credential,
+// for consumers to tell the credentials to fix apart from the other failures.
+const FaultCredentialsInvalid = "bmc_credentials_invalid"
+
+// openBMC opens the BMC session, when the login fails with an authentication error,
+// the BMC credential is refreshed from fleetdb, since it may have been rotated after the asset lookup,
+// and the login is retried once with the refreshed credential.
+//
+// errCredentialsInvalid is returned when the fleetdb credential is rejected by the BMC.
+func (th *TaskHandler) openBMC(ctx context.Context) error {
+	err := th.bmcClient.Open(ctx)
+	if err == nil || th.cfg.Dryrun || bmc.ErrorClassOf(err) != bmc.ErrorClassAuth {
+		return err
+	}
+
+	th.logger.WithError(err).Warn("bmc authentication failed, refreshing credentials")
+
+	refreshed, errRefresh := th.fleetdb.RefreshBMCCredential(ctx, th.server)
+	if errRefresh != nil {
+		return errors.Wrap(err, "error refreshing bmc credentials: "+errRefresh.Error())
+	}
+
+	if !refreshed {
+		// retrying with the same credential is likely to lock out the BMC account
+		return th.credentialsInvalid(err)
+	}
+
+	th.bmcClient, errRefresh = bmc.NewBMCClient(th.server, &th.cfg.BMC, th.bmcResets, th.facility, th.logger)
+	if errRefresh != nil {
+		return errRefresh
+	}
+
+	err = th.bmcClient.Open(ctx)
+	if bmc.ErrorClassOf(err) == bmc.ErrorClassAuth {
+		return th.credentialsInvalid(err)
+	}
+
+	if err == nil {
+		th.logger.Info("bmc login succeeded with the refreshed credentials")
+	}
+
+	return err
+}
+
+// credentialsInvalid records the credentials invalid fault on the task, published along with the failed status.
+func (th *TaskHandler) credentialsInvalid(err error) error {
+	metrics.BMCCredentialInvalid(th.server.Vendor, th.facility)
+
+	th.task.Fault = &rctypes.Fault{FailAt: FaultCredentialsInvalid}
+
+	return errors.Wrap(errCredentialsInvalid, err.Error())
+}
@@ -0,0 +1,194 @@
+package bioscfg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/config"
+)
+
+// Steps of a condition with a configurable deadline.
+const (
+	stepTask        = "task"
+	stepConnect     = "connect"
+	stepPreflight   = "preflight"
+	stepFetchConfig = "fetch_config"
+	stepReboot      = "reboot"
+	stepBiosJob     = "bios_job"
+	stepVerify      = "verify"
+
+	// time given to publish the failed status of a condition which exceeded its deadline.
+	deadlineReportTimeout = 30 * time.Second
+)
+
+var (
+	deadlineSteps = []string{stepConnect, stepPreflight, stepFetchConfig, stepReboot, stepBiosJob, stepVerify}
+
+	errDeadlineExceeded = errors.New("deadline exceeded")
+	errDeadlineConfig   = errors.New("deadline configuration error")
+	errDeadlineParam    = errors.New("invalid deadline parameter")
+)
+
+// deadlineError is the cause of a context cancelled once the deadline of a step is exceeded.
+type deadlineError struct {
+	step     string
+	deadline time.Duration
+}
+
+func (e *deadlineError) Error() string {
+	return fmt.Sprintf("%s deadline of %s exceeded", e.step, e.deadline)
+}
+
+func (e *deadlineError) Is(target error) bool {
+	return target == errDeadlineExceeded
+}
+
+// deadlines are the task and step deadlines of a condition, zero is no deadline.
+type deadlines struct {
+	task  time.Duration
+	steps map[string]time.Duration
+}
+
+// validateDeadlines verifies the configured actions and steps are known.
+func validateDeadlines(cfg *config.Deadlines) error {
+	for action := range cfg.Actions {
+		if _, ok := actionFeatures[rctypes.BiosControlAction(action)]; !ok {
+			return errors.Wrap(errDeadlineConfig, "unknown action: "+action)
+		}
+	}
+
+	for step := range cfg.Steps {
+		if !validStep(step) {
+			return errors.Wrap(errDeadlineConfig, "unknown step: "+step)
+		}
+	}
+
+	return nil
+}
+
+func validStep(step string) bool {
+	for _, s := range deadlineSteps {
+		if s == step {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newDeadlines returns the deadlines of the task action, the task parameters override the configured deadlines,
+// within the configured maximum.
+func newDeadlines(cfg *config.Deadlines, params *TaskParameters) (*deadlines, error) {
+	d := &deadlines{task: cfg.Task, steps: make(map[string]time.Duration, len(deadlineSteps))}
+
+	if actionDeadline, ok := cfg.Actions[string(params.Action)]; ok {
+		d.task = actionDeadline
+	}
+
+	for step, deadline := range cfg.Steps {
+		d.steps[step] = deadline
+	}
+
+	if params.Deadline != "" {
+		deadline, err := parseDeadline(params.Deadline, cfg.Max)
+		if err != nil {
+			return nil, errors.Wrap(err, "deadline")
+		}
+
+		d.task = deadline
+	}
+
+	for step, value := range params.StepDeadlines {
+		if !validStep(step) {
+			return nil, errors.Wrap(errDeadlineParam, "unknown step: "+step)
+		}
+
+		deadline, err := parseDeadline(value, cfg.Max)
+		if err != nil {
+			return nil, errors.Wrap(err, "step_deadlines."+step)
+		}
+
+		d.steps[step] = deadline
+	}
+
+	return d, nil
+}
+
+func parseDeadline(value string, maxDeadline time.Duration) (time.Duration, error) {
+	deadline, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrap(errDeadlineParam, err.Error())
+	}
+
+	if deadline <= 0 {
+		return 0, errors.Wrap(errDeadlineParam, "must be positive: "+value)
+	}
+
+	if maxDeadline > 0 && deadline > maxDeadline {
+		return 0, errors.Wrap(errDeadlineParam, fmt.Sprintf("%s exceeds the maximum of %s", value, maxDeadline))
+	}
+
+	return deadline, nil
+}
+
+// withDeadline returns a context cancelled once the deadline of the step is exceeded,
+// the step is named in the context cause.
+func withDeadline(ctx context.Context, step string, deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeoutCause(ctx, deadline, &deadlineError{step: step, deadline: deadline})
+}
+
+// stepDeadline returns a context with the deadline of the step, when one is set.
+func (th *TaskHandler) stepDeadline(ctx context.Context, step string) (context.Context, context.CancelFunc) {
+	if th.deadlines == nil {
+		return context.WithCancel(ctx)
+	}
+
+	return withDeadline(ctx, step, th.deadlines.steps[step])
+}
+
+// deadlineExceeded returns the deadline error when the context was cancelled by a task or step deadline.
+func deadlineExceeded(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, errDeadlineExceeded) {
+		return cause
+	}
+
+	return nil
+}
+
+// stepError names the exceeded deadline of a step, or the cancellation, in the returned error.
+func stepError(ctx context.Context, err error) error {
+	if cause := stopCause(ctx); err != nil && cause != nil {
+		return errors.Wrap(err, cause.Error())
+	}
+
+	return err
+}
+
+// write runs a change on the BMC, the change is not started once a deadline is exceeded or the condition is cancelled,
+// and once started it is not cancelled, to not leave a partially applied change,
+// it is instead bounded by the configured write timeout. The change is recorded to be reported when the condition is stopped.
+func (th *TaskHandler) write(ctx context.Context, change string, apply func(context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(context.Cause(ctx), "change not started: "+change)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), th.cfg.Deadlines.Write)
+	defer cancel()
+
+	if err := apply(ctx); err != nil {
+		th.applied = append(th.applied, change+" (failed, may be partially applied)")
+		return err
+	}
+
+	th.applied = append(th.applied, change)
+
+	return nil
+}
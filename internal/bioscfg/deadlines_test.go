@@ -0,0 +1,172 @@
+package bioscfg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/bioscfg/internal/config"
+)
+
+func TestNewDeadlines(t *testing.T) {
+	cfg := &config.Deadlines{
+		Task:    time.Hour,
+		Actions: map[string]time.Duration{string(rctypes.SetConfig): 2 * time.Hour},
+		Steps:   map[string]time.Duration{stepReboot: 20 * time.Minute},
+		Max:     3 * time.Hour,
+	}
+
+	params := func(action rctypes.BiosControlAction, deadline string, steps map[string]string) *TaskParameters {
+		p := &TaskParameters{Deadline: deadline, StepDeadlines: steps}
+		p.Action = action
+
+		return p
+	}
+
+	cases := []struct {
+		name          string
+		params        *TaskParameters
+		expectedTask  time.Duration
+		expectedSteps map[string]time.Duration
+		expectErr     bool
+	}{
+		{
+			"configured deadlines",
+			params(rctypes.ResetConfig, "", nil),
+			time.Hour,
+			map[string]time.Duration{stepReboot: 20 * time.Minute},
+			false,
+		},
+		{
+			"per action override",
+			params(rctypes.SetConfig, "", nil),
+			2 * time.Hour,
+			map[string]time.Duration{stepReboot: 20 * time.Minute},
+			false,
+		},
+		{
+			"parameter overrides",
+			params(rctypes.SetConfig, "90m", map[string]string{stepReboot: "30m", stepBiosJob: "1h"}),
+			90 * time.Minute,
+			map[string]time.Duration{stepReboot: 30 * time.Minute, stepBiosJob: time.Hour},
+			false,
+		},
+		{"parameter override at max", params(rctypes.ResetConfig, "3h", nil), 3 * time.Hour, map[string]time.Duration{stepReboot: 20 * time.Minute}, false},
+		{"parameter override above max", params(rctypes.ResetConfig, "4h", nil), 0, nil, true},
+		{"step parameter override above max", params(rctypes.ResetConfig, "", map[string]string{stepReboot: "4h"}), 0, nil, true},
+		{"unknown step", params(rctypes.ResetConfig, "", map[string]string{"firmware": "1h"}), 0, nil, true},
+		{"zero deadline", params(rctypes.ResetConfig, "0s", nil), 0, nil, true},
+		{"negative step deadline", params(rctypes.ResetConfig, "", map[string]string{stepReboot: "-1m"}), 0, nil, true},
+		{"invalid deadline", params(rctypes.ResetConfig, "an hour", nil), 0, nil, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := newDeadlines(cfg, tc.params)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, errDeadlineParam)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTask, d.task)
+			assert.Equal(t, tc.expectedSteps, d.steps)
+		})
+	}
+}
+
+func TestParseDeadlineWithoutMax(t *testing.T) {
+	deadline, err := parseDeadline("48h", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, deadline)
+}
+
+func TestValidateDeadlines(t *testing.T) {
+	cases := []struct {
+		name      string
+		cfg       *config.Deadlines
+		expectErr bool
+	}{
+		{"empty", &config.Deadlines{}, false},
+		{
+			"known actions and steps",
+			&config.Deadlines{
+				Actions: map[string]time.Duration{string(rctypes.SetConfig): time.Hour, string(SetBootMode): time.Hour},
+				Steps:   map[string]time.Duration{stepConnect: time.Minute, stepVerify: time.Minute},
+			},
+			false,
+		},
+		{"unknown action", &config.Deadlines{Actions: map[string]time.Duration{"firmware_install": time.Hour}}, true},
+		{"unknown step", &config.Deadlines{Steps: map[string]time.Duration{stepTask: time.Hour}}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateDeadlines(tc.cfg)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, errDeadlineConfig)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStepError(t *testing.T) {
+	errStep := errors.New("bmc request failed")
+
+	t.Run("step deadline exceeded", func(t *testing.T) {
+		ctx, cancel := withDeadline(context.Background(), stepReboot, time.Millisecond)
+		defer cancel()
+
+		<-ctx.Done()
+
+		err := stepError(ctx, errStep)
+		assert.ErrorIs(t, err, errStep)
+		assert.Contains(t, err.Error(), "reboot deadline of 1ms exceeded")
+
+		var deadlineErr *deadlineError
+		assert.True(t, errors.As(context.Cause(ctx), &deadlineErr))
+		assert.Equal(t, stepReboot, deadlineErr.step)
+		assert.ErrorIs(t, deadlineExceeded(ctx), errDeadlineExceeded)
+	})
+
+	t.Run("parent deadline exceeded", func(t *testing.T) {
+		taskCtx, cancelTask := withDeadline(context.Background(), stepTask, time.Millisecond)
+		defer cancelTask()
+
+		ctx, cancel := withDeadline(taskCtx, stepReboot, time.Hour)
+		defer cancel()
+
+		<-ctx.Done()
+
+		assert.Contains(t, stepError(ctx, errStep).Error(), "task deadline of 1ms exceeded")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errors.Wrap(errCancelled, "wrong rack"))
+
+		err := stepError(ctx, errStep)
+		assert.ErrorIs(t, err, errStep)
+		assert.Contains(t, err.Error(), "wrong rack")
+		assert.NoError(t, deadlineExceeded(ctx))
+	})
+
+	t.Run("no deadline exceeded", func(t *testing.T) {
+		ctx, cancel := withDeadline(context.Background(), stepReboot, 0)
+		defer cancel()
+
+		assert.Equal(t, errStep, stepError(ctx, errStep))
+		assert.NoError(t, stepError(ctx, nil))
+
+		cancel()
+		assert.Equal(t, errStep, stepError(ctx, errStep), "plain cancellation is not a stop cause")
+	})
+}
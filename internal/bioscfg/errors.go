@@ -6,4 +6,16 @@ var (
 	errInvalidConditionParams = errors.New("invalid condition parameters")
 	errTaskConv               = errors.New("error in generic Task conversion")
 	errUnsupportedAction      = errors.New("unsupported action")
+	errPreflight              = errors.New("pre-flight check failed")
+	errBiosJobTimeout         = errors.New("timeout waiting for bios job")
+	errBiosJobNotFound        = errors.New("bios job not found")
+	errBiosJobFailed          = errors.New("bios job failed")
+	errBiosJobReboot          = errors.New("failed to reboot server to run bios job")
+	errVerifyTimeout          = errors.New("timeout verifying change")
+	errOverrideRequired       = errors.New("override parameter required")
+	errHostBootTimeout        = errors.New("timeout waiting for host to boot")
+	errHostStuck              = errors.New("host stuck in post")
+	errCredentialsInvalid     = errors.New("bmc credentials invalid")
+	errInterrupted            = errors.New("interrupted by controller shutdown")
+	errCancelled              = errors.New("condition cancelled")
 )
@@ -6,37 +6,64 @@ import (
 
 	"github.com/metal-toolbox/ctrl"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/metal-toolbox/bioscfg/internal/capabilities"
 	"github.com/metal-toolbox/bioscfg/internal/config"
 	"github.com/metal-toolbox/bioscfg/internal/model"
+	"github.com/metal-toolbox/bioscfg/internal/publisher"
 	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
-	"github.com/metal-toolbox/bioscfg/internal/store/fleetdb"
 )
 
 type TaskHandler struct {
 	logger       *logrus.Entry
 	cfg          *config.Configuration
-	fleetdb      *fleetdb.Store
+	fleetdb      inventory
+	bmcResets    bmc.ResetLimiter
+	capabilities *capabilities.Matrix
+	deadlines    *deadlines
 	bmcClient    bmc.BMC
-	publisher    ctrl.Publisher
+	publisher    *publisher.StatusPublisher
 	server       *model.Asset
 	task         *Task
 	startTS      time.Time
 	controllerID string
+	shutdown     *shutdown
+	// cancellations is set when the cancellation requests are watched
+	cancellations cancellationWatcher
+	// applied are the changes made on the BMC
+	applied []string
+	// local is set for the conditions run from the command line, which allow file:// BIOS config URLs
+	// and the command line only actions
+	local bool
+	// facility is the facility the condition was received for, the metrics are labelled with
+	facility string
 }
 
-func (th *TaskHandler) HandleTask(ctx context.Context, genTask *rctypes.Task[any, any], publisher ctrl.Publisher) error {
+func (th *TaskHandler) HandleTask(ctx context.Context, genTask *rctypes.Task[any, any], statusPublisher ctrl.Publisher) (err error) {
+	ctx, cancel := th.shutdown.handlerContext(ctx)
+	defer cancel()
+
 	ctx, span := otel.Tracer(pkgName).Start(
 		ctx,
 		"bioscfg.HandleTask",
 	)
 	defer span.End()
 
-	var err error
-	th.publisher = publisher
+	th.publisher, err = publisher.New(statusPublisher, &th.cfg.StatusPublisher, th.logger)
+	if err != nil {
+		return err
+	}
+
+	// send any coalesced status update before the task is released
+	defer func() {
+		if err := th.publisher.Flush(); err != nil {
+			th.logger.WithError(err).Warn("failed to publish condition status")
+		}
+	}()
 
 	// Ungeneric the task
 	th.task, err = newTask(genTask)
@@ -49,6 +76,25 @@ func (th *TaskHandler) HandleTask(ctx context.Context, genTask *rctypes.Task[any
 		return err
 	}
 
+	defer func() { th.reportInterrupted(ctx, err) }()
+
+	if err := th.checkpoint(); err != nil {
+		return err
+	}
+
+	th.deadlines, err = newDeadlines(&th.cfg.Deadlines, th.task.Parameters)
+	if err != nil {
+		return th.failedWithError(ctx, "invalid task parameters", err)
+	}
+
+	ctx, stopWatch := th.watchCancellation(ctx)
+	defer stopWatch()
+
+	ctx, cancelDeadline := withDeadline(ctx, stepTask, th.deadlines.task)
+	defer cancelDeadline()
+
+	defer th.reportStopped(ctx)
+
 	// Get Server
 	th.server, err = th.fleetdb.AssetByID(ctx, th.task.Parameters.AssetID)
 	if err != nil {
@@ -73,20 +119,29 @@ func (th *TaskHandler) HandleTask(ctx context.Context, genTask *rctypes.Task[any
 		},
 	)
 
+	err = th.checkCapabilities(ctx, false)
+	if err != nil {
+		return th.failedWithError(ctx, "capability check failed", err)
+	}
+
 	// Get BMC Client
 	if th.cfg.Dryrun { // Fake BMC
 		th.bmcClient = bmc.NewDryRunBMCClient(th.server)
 		th.logger.Warn("Running BMC in Dryrun mode")
 	} else {
-		th.bmcClient = bmc.NewBMCClient(th.server, th.logger)
+		th.bmcClient, err = bmc.NewBMCClient(th.server, &th.cfg.BMC, th.bmcResets, th.facility, th.logger)
+		if err != nil {
+			return th.failedWithError(ctx, "bmc client init failed", err)
+		}
 	}
 
-	err = th.bmcClient.Open(ctx)
+	err = th.connect(ctx)
 	if err != nil {
-		return th.failedWithError(ctx, "bmc connection failed to connect", err)
+		return err
 	}
 	defer func() {
-		if err := th.bmcClient.Close(ctx); err != nil {
+		// the session is closed when the handler is aborted on shutdown
+		if err := th.bmcClient.Close(context.WithoutCancel(ctx)); err != nil {
 			err := th.failedWithError(ctx, "bmc connection close error", err)
 			if err != nil {
 				th.logger.WithError(err).Error("bmc connection close error, and then failed to set condition status to failed")
@@ -97,6 +152,28 @@ func (th *TaskHandler) HandleTask(ctx context.Context, genTask *rctypes.Task[any
 	return th.run(ctx)
 }
 
+// connect opens the BMC session, within the connect step deadline.
+func (th *TaskHandler) connect(ctx context.Context) error {
+	ctx, cancel := th.stepDeadline(ctx, stepConnect)
+	defer cancel()
+
+	err := th.bmcClient.CheckReachable(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "pre-flight check failed, bmc unreachable", err)
+	}
+
+	err = th.openBMC(ctx)
+	if errors.Is(err, errCredentialsInvalid) {
+		return th.failedWithError(ctx, "bmc login failed with the refreshed fleetdb credential", err)
+	}
+
+	if err != nil {
+		return th.failedWithError(ctx, "bmc connection failed to connect", err)
+	}
+
+	return nil
+}
+
 func (th *TaskHandler) run(ctx context.Context) error {
 	ctx, span := otel.Tracer(pkgName).Start(
 		ctx,
@@ -105,11 +182,35 @@ func (th *TaskHandler) run(ctx context.Context) error {
 	)
 	defer span.End()
 
+	err := th.runPreflight(ctx)
+	switch {
+	case errors.Is(err, errUnsupportedAction):
+		return th.failedWithError(ctx, string(th.task.Parameters.Action), errUnsupportedAction)
+	case errors.Is(err, capabilities.ErrUnsupported):
+		return th.failedWithError(ctx, "capability check failed", err)
+	case errors.Is(err, errPreflight):
+		return th.failedWithError(ctx, "pre-flight checks failed", err)
+	case err != nil:
+		return err
+	}
+
+	if err := th.checkpoint(); err != nil {
+		return err
+	}
+
 	th.logger.Info("running condition action")
-	err := th.publishActive(ctx, "running condition action")
+	err = th.publishActive(ctx, "running condition action")
 	if err != nil {
 		return err
 	}
 
 	return th.handleAction(ctx)
 }
+
+// runPreflight runs the pre-flight checks within the preflight step deadline.
+func (th *TaskHandler) runPreflight(ctx context.Context) error {
+	ctx, cancel := th.stepDeadline(ctx, stepPreflight)
+	defer cancel()
+
+	return stepError(ctx, th.preflight(ctx))
+}
@@ -0,0 +1,88 @@
+package bioscfg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/health"
+)
+
+var (
+	errNotListening = errors.New("not listening for conditions")
+	errShuttingDown = errors.New("shutting down")
+)
+
+// Health returns the health server with the controller liveness and readiness checks.
+//
+// The liveness checks only fail when the controller stopped listening without shutting down,
+// a NATS connection reconnecting or a shutdown drain is reported by the readiness checks instead,
+// to not have the controller restarted while it changes the BIOS settings.
+func (bc *BiosCfg) Health() *health.Server {
+	s := health.New()
+	if bc.natsConn != nil {
+		s.Liveness("listener", bc.checkListening)
+		s.Readiness("nats", bc.checkNats)
+	} else {
+		s.Liveness("orchestrator", bc.checkListening)
+	}
+
+	if bc.fleetdb != nil {
+		s.Readiness("fleetdb", bc.fleetdb.Ping)
+	}
+
+	for _, f := range bc.facilities {
+		if f.fleetdbOverride {
+			s.Readiness("fleetdb-"+f.code, f.fleetdb.Ping)
+		}
+	}
+	s.Readiness("handlers", bc.checkHandlers)
+
+	return s
+}
+
+// checkNats reports the state of the NATS connection of the KV stores, the controller library does not expose
+// the connections of the facility listeners, a listener returns when its connection fails, stopping the controller.
+func (bc *BiosCfg) checkNats(_ context.Context) (string, error) {
+	if status := bc.natsConn.Status(); status != nats.CONNECTED {
+		return "", errors.New("nats connection " + status.String())
+	}
+
+	return "connected", nil
+}
+
+// checkListening reports whether the controller is still listening for conditions, or draining them on shutdown.
+func (bc *BiosCfg) checkListening(_ context.Context) (string, error) {
+	switch {
+	case bc.listening.Load():
+		return "listening", nil
+	case bc.shutdown.isStopping():
+		return "shutting down", nil
+	default:
+		return "", errNotListening
+	}
+}
+
+// checkHandlers reports the in-flight conditions against the concurrency of the facilities,
+// saturated handlers are the normal state of a busy controller and only reported, the check fails when shutting down.
+func (bc *BiosCfg) checkHandlers(_ context.Context) (string, error) {
+	concurrency := 0
+	for _, f := range bc.facilities {
+		concurrency += f.concurrency
+	}
+
+	active := int(bc.shutdown.active.Load())
+	detail := fmt.Sprintf("%d/%d handlers active", active, concurrency)
+
+	if bc.shutdown.isStopping() {
+		return "", errors.Wrap(errShuttingDown, detail)
+	}
+
+	if active >= concurrency {
+		detail += ", saturated"
+	}
+
+	return detail, nil
+}
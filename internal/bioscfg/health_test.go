@@ -0,0 +1,136 @@
+package bioscfg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckListening(t *testing.T) {
+	cases := []struct {
+		name      string
+		listening bool
+		stopping  bool
+		expectErr bool
+	}{
+		{"listening", true, false, false},
+		{"draining on shutdown", false, true, false},
+		{"listener stopped", false, false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bc := &BiosCfg{shutdown: newShutdown()}
+			bc.listening.Store(tc.listening)
+
+			if tc.stopping {
+				bc.shutdown.begin()
+			}
+
+			_, err := bc.checkListening(context.Background())
+			if tc.expectErr {
+				assert.ErrorIs(t, err, errNotListening)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckHandlers(t *testing.T) {
+	cases := []struct {
+		name       string
+		facilities []*facility
+		active     int32
+		stopping   bool
+		wantDetail string
+		wantErr    bool
+	}{
+		{"idle", []*facility{{code: "da1", concurrency: 2}}, 0, false, "0/2 handlers active", false},
+		{"saturated", []*facility{{code: "da1", concurrency: 2}}, 2, false, "2/2 handlers active, saturated", false},
+		{"facilities", []*facility{{code: "da1", concurrency: 2}, {code: "ams1", concurrency: 3}}, 4, false, "4/5 handlers active", false},
+		{"stopping", []*facility{{code: "da1", concurrency: 2}}, 1, true, "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bc := &BiosCfg{facilities: tc.facilities, shutdown: newShutdown()}
+			bc.shutdown.active.Store(tc.active)
+
+			if tc.stopping {
+				bc.shutdown.begin()
+			}
+
+			detail, err := bc.checkHandlers(context.Background())
+			if tc.wantErr {
+				assert.ErrorIs(t, err, errShuttingDown)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantDetail, detail)
+		})
+	}
+}
+
+// fakeNatsServer accepts NATS client connections, answering the connection handshake and the pings.
+func fakeNatsServer(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+
+					if strings.HasPrefix(line, "PING") {
+						fmt.Fprint(conn, "PONG\r\n")
+					}
+				}
+			}()
+		}
+	}()
+
+	return "nats://" + ln.Addr().String()
+}
+
+func TestCheckNats(t *testing.T) {
+	conn, err := nats.Connect(fakeNatsServer(t), nats.NoReconnect())
+	require.NoError(t, err)
+
+	bc := &BiosCfg{natsConn: conn}
+
+	detail, err := bc.checkNats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "connected", detail)
+
+	conn.Close()
+
+	_, err = bc.checkNats(context.Background())
+	assert.ErrorContains(t, err, "nats connection CLOSED")
+}
@@ -0,0 +1,47 @@
+package bioscfg
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
+)
+
+var errNoInventory = errors.New("not available without fleetdb")
+
+// inventory is the store the handler looks up assets and their credentials from.
+type inventory interface {
+	AssetByID(ctx context.Context, id uuid.UUID) (*model.Asset, error)
+	RefreshBMCCredential(ctx context.Context, asset *model.Asset) (bool, error)
+	BiosPassword(ctx context.Context, id uuid.UUID) (*model.BiosPassword, error)
+	BiosPasswordRotated(ctx context.Context, id uuid.UUID, password string, rotatedAt time.Time) error
+}
+
+// staticInventory serves the single asset given on the command line, when fleetdb is not used.
+type staticInventory struct {
+	asset *model.Asset
+}
+
+func (s *staticInventory) AssetByID(_ context.Context, id uuid.UUID) (*model.Asset, error) {
+	if id != s.asset.ID {
+		return nil, errors.Wrap(errNoInventory, "unknown asset "+id.String())
+	}
+
+	return s.asset, nil
+}
+
+// RefreshBMCCredential reports the credential as unchanged, it is only given on the command line.
+func (s *staticInventory) RefreshBMCCredential(_ context.Context, _ *model.Asset) (bool, error) {
+	return false, nil
+}
+
+func (s *staticInventory) BiosPassword(_ context.Context, _ uuid.UUID) (*model.BiosPassword, error) {
+	return nil, errors.Wrap(errNoInventory, "bios password")
+}
+
+func (s *staticInventory) BiosPasswordRotated(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+	return errors.Wrap(errNoInventory, "bios password")
+}
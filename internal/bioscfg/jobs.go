@@ -0,0 +1,197 @@
+package bioscfg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+)
+
+// biosJobIDs returns the IDs of the BIOS jobs currently listed on the BMC,
+// nil is returned when the BMC does not expose its BIOS jobs.
+func (th *TaskHandler) biosJobIDs(ctx context.Context) (map[string]bool, error) {
+	jobs, err := th.bmcClient.BiosJobs(ctx)
+	if err != nil {
+		if bmc.ErrorClassOf(err) == bmc.ErrorClassUnsupported {
+			th.logger.Debug("bios jobs not supported by bmc, job tracking disabled")
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	ids := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		ids[job.ID] = true
+	}
+
+	return ids, nil
+}
+
+// createdBiosJob returns the BIOS job created since the known jobs were listed,
+// nil is returned when no job was created or the BMC does not expose its BIOS jobs.
+func (th *TaskHandler) createdBiosJob(ctx context.Context, known map[string]bool) (*bmc.Job, error) {
+	if known == nil {
+		return nil, nil
+	}
+
+	jobs, err := th.bmcClient.BiosJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *bmc.Job
+	for _, job := range jobs {
+		if known[job.ID] {
+			continue
+		}
+
+		// prefer the job still to be run, if more than one was created
+		if created == nil || created.Finished() {
+			created = job
+		}
+	}
+
+	return created, nil
+}
+
+// trackBiosJob reports the BIOS job created by the action, and when configured waits for it to complete,
+// the condition is marked as failed when the job fails or does not complete in time.
+func (th *TaskHandler) trackBiosJob(ctx context.Context, job *bmc.Job, rebooted bool) error {
+	if !th.cfg.BiosJobs.Wait {
+		err := th.publishActive(ctx, fmt.Sprintf("bios job %s created, state: %s", job.ID, job.State))
+		if err != nil {
+			return err
+		}
+
+		return th.successful(ctx, fmt.Sprintf("bios job %s %s, not waiting for completion", job.ID, job.State))
+	}
+
+	job, err := th.awaitBiosJob(ctx, job, rebooted)
+	if err != nil {
+		return th.biosJobFailed(ctx, job, err)
+	}
+
+	return th.successful(ctx, fmt.Sprintf("bios job %s %s", job.ID, job.State))
+}
+
+// awaitBiosJob waits for the BIOS job to complete, the server is rebooted to run the job when configured,
+// an error is returned when the job fails or does not complete in time, or within the bios_job step deadline.
+func (th *TaskHandler) awaitBiosJob(ctx context.Context, job *bmc.Job, rebooted bool) (*bmc.Job, error) {
+	ctx, cancel := th.stepDeadline(ctx, stepBiosJob)
+	defer cancel()
+
+	job, err := th.waitBiosJobDone(ctx, job, rebooted)
+
+	return job, stepError(ctx, err)
+}
+
+func (th *TaskHandler) waitBiosJobDone(ctx context.Context, job *bmc.Job, rebooted bool) (*bmc.Job, error) {
+	err := th.publishActive(ctx, fmt.Sprintf("bios job %s created, state: %s", job.ID, job.State))
+	if err != nil {
+		return job, err
+	}
+
+	if !job.Finished() && !rebooted && th.cfg.BiosJobs.Reboot {
+		if err := th.rebootForBiosJob(ctx, job); err != nil {
+			return job, errors.Wrap(errBiosJobReboot, err.Error())
+		}
+	}
+
+	job, err = th.waitBiosJob(ctx, job)
+	if err != nil {
+		return job, err
+	}
+
+	if job.State == bmc.JobStateFailed {
+		return job, errors.Wrap(errBiosJobFailed, job.Message)
+	}
+
+	return job, nil
+}
+
+// biosJobFailed marks the condition as failed for the errors returned by awaitBiosJob,
+// errors publishing the condition status are returned as is.
+func (th *TaskHandler) biosJobFailed(ctx context.Context, job *bmc.Job, err error) error {
+	switch {
+	case errors.Is(err, errBiosJobTimeout), errors.Is(err, errBiosJobFailed), errors.Is(err, errBiosJobReboot):
+		return th.failedWithError(ctx, "bios job "+job.ID, err)
+	default:
+		return err
+	}
+}
+
+// rebootForBiosJob reboots the server, or powers it on when off, for the BMC to run the pending BIOS job.
+func (th *TaskHandler) rebootForBiosJob(ctx context.Context, job *bmc.Job) error {
+	state, err := th.bmcClient.GetPowerState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if state != model.PowerStateOn {
+		return th.reboot(ctx, model.PowerActionOn, job)
+	}
+
+	return th.reboot(ctx, model.PowerActionReset, job)
+}
+
+// waitBiosJob polls the BMC until the job is finished, or the configured timeout is reached,
+// the cause is returned when the condition is stopped before.
+func (th *TaskHandler) waitBiosJob(ctx context.Context, job *bmc.Job) (*bmc.Job, error) {
+	cfg := th.cfg.BiosJobs
+
+	waitCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for !job.Finished() {
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return job, context.Cause(ctx)
+			}
+
+			return job, errors.Wrap(errBiosJobTimeout, fmt.Sprintf("last state: %s, after %s", job.State, cfg.Timeout))
+		case <-ticker.C:
+		}
+
+		current, err := th.biosJob(waitCtx, job.ID)
+		if err != nil {
+			// the BMC may be unresponsive while the server reboots, keep polling until the timeout
+			th.logger.WithError(err).WithField("jobID", job.ID).Warn("error polling bios job status")
+			continue
+		}
+
+		if current.State != job.State {
+			err := th.publishActive(ctx, fmt.Sprintf("bios job %s state: %s", current.ID, current.State))
+			if err != nil {
+				return current, err
+			}
+		}
+
+		job = current
+	}
+
+	return job, nil
+}
+
+func (th *TaskHandler) biosJob(ctx context.Context, id string) (*bmc.Job, error) {
+	jobs, err := th.bmcClient.BiosJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+
+	return nil, errors.Wrap(errBiosJobNotFound, id)
+}
@@ -0,0 +1,192 @@
+package bioscfg
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metal-toolbox/bioscfg/internal/capabilities"
+	"github.com/metal-toolbox/bioscfg/internal/config"
+	"github.com/metal-toolbox/bioscfg/internal/model"
+	"github.com/metal-toolbox/bioscfg/internal/publisher"
+	"github.com/metal-toolbox/bioscfg/internal/store/fleetdb"
+)
+
+// controller ID reported by conditions run from the command line.
+const localControllerID = "bioscfg-cli"
+
+var (
+	errLocalTarget = errors.New("invalid target server")
+	errLocalFailed = errors.New("condition did not succeed")
+)
+
+// LocalTarget is the server to run an action on from the command line,
+// looked up in fleetdb by ID, or given by its BMC address and credentials.
+type LocalTarget struct {
+	ServerID    string
+	BMCAddress  string
+	BMCUser     string
+	BMCPassFile string
+	// Vendor and Model are matched against the capability matrix and the BMC client overrides.
+	Vendor string
+	Model  string
+}
+
+// RunLocal runs the action on the target server with the condition handler, without NATS,
+// the condition status is printed to out, an error is returned when the condition does not succeed.
+func RunLocal(ctx context.Context, configFile, logLevel string, target *LocalTarget, params *TaskParameters, out io.Writer) error {
+	cfg, logger, err := loadLocalConfig(configFile, logLevel)
+	if err != nil {
+		return err
+	}
+
+	store, assetID, err := localInventory(ctx, cfg, target, logger)
+	if err != nil {
+		return err
+	}
+
+	params.AssetID = assetID
+
+	task, err := localTask(cfg, params)
+	if err != nil {
+		return err
+	}
+
+	return runLocalTask(ctx, cfg, logger, store, task, out)
+}
+
+// loadLocalConfig loads the configuration, logs are written to stderr, the condition status to stdout.
+func loadLocalConfig(configFile, logLevel string) (*config.Configuration, *logrus.Logger, error) {
+	cfg, err := config.Load(configFile, logLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger := logrus.New()
+	logger.Out = os.Stderr
+	logger.Level, err = logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateDeadlines(&cfg.Deadlines); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, logger, nil
+}
+
+// runLocalTask runs the task with the condition handler, the condition status is printed to out.
+func runLocalTask(ctx context.Context, cfg *config.Configuration, logger *logrus.Logger, store inventory, task *rctypes.Task[any, any], out io.Writer) error {
+	matrix, err := capabilities.New(cfg.Capabilities)
+	if err != nil {
+		return err
+	}
+
+	console := publisher.NewConsole(out)
+	th := &TaskHandler{
+		cfg:          cfg,
+		logger:       logger.WithField("controllerID", localControllerID),
+		controllerID: localControllerID,
+		fleetdb:      store,
+		capabilities: matrix,
+		local:        true,
+		startTS:      time.Now(),
+		facility:     cfg.FacilityCode,
+	}
+
+	if err := th.HandleTask(ctx, task, console); err != nil {
+		return err
+	}
+
+	if state := console.State(); state != rctypes.Succeeded {
+		return errors.Wrap(errLocalFailed, string(state)+": "+console.LastStatus())
+	}
+
+	return nil
+}
+
+// localInventory returns the inventory to look up the target server from, with the ID of the server.
+func localInventory(ctx context.Context, cfg *config.Configuration, target *LocalTarget, logger *logrus.Logger) (inventory, uuid.UUID, error) {
+	id := uuid.New()
+	if target.ServerID != "" {
+		var err error
+		if id, err = uuid.Parse(target.ServerID); err != nil {
+			return nil, uuid.Nil, errors.Wrap(errLocalTarget, "server id: "+err.Error())
+		}
+	}
+
+	if target.BMCAddress == "" {
+		if target.ServerID == "" {
+			return nil, uuid.Nil, errors.Wrap(errLocalTarget, "a server id, or a bmc address is required")
+		}
+
+		store, err := fleetdb.New(ctx, &cfg.Endpoints.FleetDB, logger)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+
+		return store, id, nil
+	}
+
+	addr, err := model.ParseBMCAddress(target.BMCAddress)
+	if err != nil {
+		return nil, uuid.Nil, errors.Wrap(errLocalTarget, err.Error())
+	}
+
+	// the dryrun BMC accepts any credentials
+	if !cfg.Dryrun && (target.BMCUser == "" || target.BMCPassFile == "") {
+		return nil, uuid.Nil, errors.Wrap(errLocalTarget, "the bmc user and password file are required with a bmc address")
+	}
+
+	var password []byte
+	if target.BMCPassFile != "" {
+		password, err = os.ReadFile(target.BMCPassFile)
+		if err != nil {
+			return nil, uuid.Nil, errors.Wrap(errLocalTarget, "bmc password file: "+err.Error())
+		}
+	}
+
+	asset := &model.Asset{
+		ID:           id,
+		BmcAddress:   addr,
+		BmcUsername:  target.BMCUser,
+		BmcPassword:  strings.TrimSpace(string(password)),
+		Vendor:       strings.ToLower(target.Vendor),
+		Model:        strings.ToLower(target.Model),
+		FacilityCode: cfg.FacilityCode,
+	}
+
+	return &staticInventory{asset: asset}, id, nil
+}
+
+// localTask returns the condition task to run the action with.
+func localTask(cfg *config.Configuration, params *TaskParameters) (*rctypes.Task[any, any], error) {
+	paramsJSON, err := params.Marshal()
+	if err != nil {
+		return nil, errors.Wrap(errInvalidConditionParams, err.Error())
+	}
+
+	now := time.Now()
+
+	return &rctypes.Task[any, any]{
+		StructVersion: rctypes.TaskVersion1,
+		ID:            uuid.New(),
+		Kind:          rctypes.BiosControl,
+		State:         rctypes.Pending,
+		Parameters:    paramsJSON,
+		Server:        &rtypes.Server{ID: params.AssetID.String()},
+		FacilityCode:  cfg.FacilityCode,
+		WorkerID:      localControllerID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
@@ -0,0 +1,251 @@
+package bioscfg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
+	orc "github.com/metal-toolbox/conditionorc/pkg/api/v1/orchestrator/client"
+	"github.com/metal-toolbox/ctrl"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/metal-toolbox/rivets/v2/events/registry"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/metal-toolbox/bioscfg/internal/config"
+)
+
+var (
+	errHandlerSlot      = errors.New("no condition handler available")
+	errConditionQuery   = errors.New("error querying the orchestrator condition")
+	errControllerPanic  = errors.New("orchestrator controller panic")
+	errOrchestratorInit = errors.New("error initializing the orchestrator client")
+)
+
+// timeout of the orchestrator API queries, as set by the controller library.
+const orchestratorQueryTimeout = 60 * time.Second
+
+// orchestratorListener runs the conditions of the configured servers, fetched from the condition orchestrator API,
+// the controller library HTTP controller fetches and runs the condition of a single server.
+type orchestratorListener struct {
+	logger       *logrus.Entry
+	pollInterval time.Duration
+	// client is shared with the HTTP controllers, the condition of a server is queried before the controller is run.
+	client  orc.Queryor
+	servers map[uuid.UUID]*ctrl.HTTPController
+	// newHandler returns the handler of a condition, with the controller ID reported for the server.
+	newHandler func(controllerID string) ctrl.TaskHandler
+	// slots caps the conditions handled at once to the configured concurrency.
+	slots chan struct{}
+}
+
+// newOrchestratorListener returns the listener of the single facility served in the orchestrator mode.
+func newOrchestratorListener(ctx context.Context, cfg *config.Configuration, logger *logrus.Entry, newHandler func(string) ctrl.TaskHandler) (*orchestratorListener, error) {
+	ocfg := &cfg.Endpoints.Orchestrator
+	fcfg := &cfg.Facilities[0]
+
+	client, err := newOrchestratorClient(ctx, ocfg)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &orchestratorListener{
+		logger:       logger,
+		pollInterval: ocfg.PollInterval,
+		client:       client,
+		servers:      make(map[uuid.UUID]*ctrl.HTTPController, len(ocfg.ServerIDs)),
+		newHandler:   newHandler,
+		slots:        make(chan struct{}, fcfg.Concurrency),
+	}
+
+	for _, id := range ocfg.ServerIDs {
+		serverID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, errors.Wrap(err, "server id "+id)
+		}
+
+		hc, err := ctrl.NewHTTPController(
+			string(rctypes.BiosControl),
+			fcfg.Code,
+			serverID,
+			rctypes.BiosControl,
+			// the client is set, the API config is then not used
+			&ctrl.OrchestratorAPIConfig{},
+			ctrl.WithNATSHTTPLogger(logger.Logger),
+			ctrl.WithOrchestratorClient(client),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		l.servers[serverID] = hc
+	}
+
+	return l, nil
+}
+
+// newOrchestratorClient returns the orchestrator API client, authenticated with the OIDC client credentials when enabled.
+func newOrchestratorClient(ctx context.Context, cfg *config.Orchestrator) (orc.Queryor, error) {
+	if !cfg.Authenticate {
+		return orc.NewClient(cfg.URL, orc.WithHTTPClient(&http.Client{Timeout: orchestratorQueryTimeout}))
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.OidcIssuerURL)
+	if err != nil {
+		return nil, errors.Wrap(errOrchestratorInit, err.Error())
+	}
+
+	oauthConfig := clientcredentials.Config{
+		ClientID:       cfg.OidcClientID,
+		ClientSecret:   cfg.OidcClientSecret,
+		TokenURL:       provider.Endpoint().TokenURL,
+		Scopes:         cfg.OidcClientScopes,
+		EndpointParams: url.Values{"audience": []string{cfg.OidcAudienceURL}},
+	}
+
+	oauthClient := oauthConfig.Client(context.Background())
+	client := &http.Client{
+		Transport: otelhttp.NewTransport(oauthClient.Transport),
+		Timeout:   orchestratorQueryTimeout,
+	}
+
+	return orc.NewClient(cfg.URL, orc.WithHTTPClient(client), orc.WithAuthToken(cfg.OidcClientSecret))
+}
+
+// listen runs the conditions of each server until the context is cancelled,
+// it returns once the handlers of the in-flight conditions have returned.
+func (l *orchestratorListener) listen(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for serverID, hc := range l.servers {
+		wg.Add(1)
+
+		go func(serverID uuid.UUID, hc *ctrl.HTTPController) {
+			defer wg.Done()
+
+			l.serve(ctx, serverID, hc)
+		}(serverID, hc)
+	}
+
+	wg.Wait()
+}
+
+// serve runs the conditions of the server one after the other, the server condition is queried every poll interval,
+// and the controller is run once a condition is queued.
+func (l *orchestratorListener) serve(ctx context.Context, serverID uuid.UUID, hc *ctrl.HTTPController) {
+	controllerID := registry.GetIDWithUUID(string(rctypes.BiosControl), serverID).String()
+	logger := l.logger.WithField("serverID", serverID.String())
+
+	for {
+		queued, err := l.conditionQueued(ctx, serverID)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			logger.WithError(err).Warn("orchestrator condition query error")
+		case !queued:
+			logger.Debug("no condition queued")
+		default:
+			handler := &slotHandler{slots: l.slots, handler: l.newHandler(controllerID)}
+			if err := l.run(ctx, hc, handler); err != nil && ctx.Err() == nil {
+				logger.WithError(err).Warn("orchestrator condition run error")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.pollInterval):
+		}
+	}
+}
+
+// conditionQueued returns true when a biosControl condition is pending or active on the server,
+// the controller library is only run then, as it expects a condition to run.
+func (l *orchestratorListener) conditionQueued(ctx context.Context, serverID uuid.UUID) (bool, error) {
+	resp, err := l.client.ConditionQuery(ctx, serverID)
+	if err != nil {
+		return false, errors.Wrap(errConditionQuery, err.Error())
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, errors.Wrap(errConditionQuery, fmt.Sprintf("status code %d, message: %s", resp.StatusCode, resp.Message))
+	}
+
+	cond := resp.Condition
+	if cond == nil || cond.Kind != rctypes.BiosControl {
+		return false, nil
+	}
+
+	return cond.State == rctypes.Pending || cond.State == rctypes.Active, nil
+}
+
+// run runs the controller once, panics of the handler are recovered by the library, those of the library,
+// as when the condition completes between the query and the run, are logged with their stack and returned as an error.
+func (l *orchestratorListener) run(ctx context.Context, hc *ctrl.HTTPController, handler ctrl.TaskHandler) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			l.logger.WithField("stack", string(debug.Stack())).Error(fmt.Sprintf("orchestrator controller panic: %v", rec))
+			err = errors.Wrap(errControllerPanic, fmt.Sprint(rec))
+		}
+	}()
+
+	return hc.Run(ctx, handler)
+}
+
+// slotHandler runs the handler once a slot is available, as the NATS controller does with its concurrency.
+type slotHandler struct {
+	slots   chan struct{}
+	handler ctrl.TaskHandler
+}
+
+func (h *slotHandler) HandleTask(ctx context.Context, task *rctypes.Task[any, any], publisher ctrl.Publisher) error {
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return errors.Wrap(errHandlerSlot, context.Cause(ctx).Error())
+	}
+
+	defer func() { <-h.slots }()
+
+	return h.handler.HandleTask(ctx, task, publisher)
+}
+
+// listenOrchestrator runs the conditions fetched from the orchestrator API until the context is cancelled,
+// the in-flight conditions are then drained.
+func (bc *BiosCfg) listenOrchestrator(ctx context.Context) error {
+	listener, err := newOrchestratorListener(ctx, bc.cfg, bc.logger, func(controllerID string) ctrl.TaskHandler {
+		return bc.newTaskHandler(bc.facilities[0], controllerID)
+	})
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		listener.listen(ctx)
+	}()
+
+	bc.listening.Store(true)
+	<-ctx.Done()
+	bc.listening.Store(false)
+
+	bc.logger.WithField("grace_period", bc.cfg.ShutdownGracePeriod.String()).Info("shutting down, draining in-flight conditions")
+	bc.shutdown.drain(bc.cfg.ShutdownGracePeriod, bc.logger)
+	<-done
+
+	return nil
+}
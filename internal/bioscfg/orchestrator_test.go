@@ -0,0 +1,226 @@
+package bioscfg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/ctrl"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/bioscfg/internal/capabilities"
+	"github.com/metal-toolbox/bioscfg/internal/config"
+	"github.com/metal-toolbox/bioscfg/internal/model"
+)
+
+// fakeOrchestrator stands in for the condition orchestrator API, serving a single condition of a server.
+type fakeOrchestrator struct {
+	mu        sync.Mutex
+	condition *rctypes.Condition
+	task      *rctypes.Task[any, any]
+	statuses  []*rctypes.StatusValue
+}
+
+func (o *fakeOrchestrator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/servers/")
+	resp := map[string]any{}
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/condition"):
+		resp["condition"] = o.condition
+	case r.Method == http.MethodGet && strings.Contains(path, "/condition-task/"):
+		resp["task"] = o.task
+	case r.Method == http.MethodPost && strings.Contains(path, "/condition-task/"):
+		task := &rctypes.Task[any, any]{}
+		if err := json.NewDecoder(r.Body).Decode(task); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Query().Get("ts_update") != "true" {
+			o.task = task
+		}
+	case r.Method == http.MethodPut && strings.Contains(path, "/condition-status/"):
+		sv := &rctypes.StatusValue{}
+		if err := json.NewDecoder(r.Body).Decode(sv); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Query().Get("ts_update") != "true" {
+			o.statuses = append(o.statuses, sv)
+			o.condition.State = rctypes.State(sv.State)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (o *fakeOrchestrator) state() rctypes.State {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.condition.State
+}
+
+// countingHandler counts the conditions handled.
+type countingHandler struct {
+	handled *atomic.Int32
+	handler ctrl.TaskHandler
+}
+
+func (h *countingHandler) HandleTask(ctx context.Context, task *rctypes.Task[any, any], publisher ctrl.Publisher) error {
+	h.handled.Add(1)
+
+	return h.handler.HandleTask(ctx, task, publisher)
+}
+
+func TestOrchestratorListener(t *testing.T) {
+	serverID := uuid.New()
+	params, err := json.Marshal(map[string]any{"asset_id": serverID.String(), "action": PendingConfig})
+	require.NoError(t, err)
+
+	orc := &fakeOrchestrator{
+		condition: &rctypes.Condition{
+			ID:         uuid.New(),
+			Kind:       rctypes.BiosControl,
+			Target:     serverID,
+			State:      rctypes.Pending,
+			Parameters: params,
+		},
+	}
+
+	srv := httptest.NewServer(orc)
+	defer srv.Close()
+
+	cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte(`
+facility: sandbox
+dryrun: true
+mode: orchestrator
+endpoints:
+  orchestrator:
+    url: `+srv.URL+`
+    server_ids: [`+serverID.String()+`]
+    poll_interval: 10ms
+`), 0o600))
+
+	cfg, err := config.Load(cfgFile, "")
+	require.NoError(t, err)
+
+	matrix, err := capabilities.New(nil)
+	require.NoError(t, err)
+
+	bmcAddr, err := model.ParseBMCAddress("127.0.0.1")
+	require.NoError(t, err)
+
+	store := &staticInventory{asset: &model.Asset{ID: serverID, BmcAddress: bmcAddr, Vendor: "dell", FacilityCode: "sandbox"}}
+	logger := logrus.NewEntry(logrus.New())
+
+	handled := &atomic.Int32{}
+	listener, err := newOrchestratorListener(context.Background(), cfg, logger, func(controllerID string) ctrl.TaskHandler {
+		return &countingHandler{handled: handled, handler: &TaskHandler{
+			cfg:          cfg,
+			logger:       logger,
+			controllerID: controllerID,
+			fleetdb:      store,
+			capabilities: matrix,
+			startTS:      time.Now(),
+		}}
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		listener.listen(ctx)
+	}()
+
+	assert.Eventually(t, func() bool { return orc.state() == rctypes.Succeeded }, 30*time.Second, 10*time.Millisecond)
+
+	// the completed condition is not run again, over several polls
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, int32(1), handled.Load())
+
+	orc.mu.Lock()
+	defer orc.mu.Unlock()
+
+	require.NotNil(t, orc.task)
+	assert.Equal(t, orc.condition.ID, orc.task.ID)
+	assert.Equal(t, rctypes.Succeeded, orc.task.State)
+	assert.Equal(t, rctypes.Succeeded, orc.condition.State)
+}
+
+func TestConditionQueued(t *testing.T) {
+	serverID := uuid.New()
+
+	cases := []struct {
+		name      string
+		condition *rctypes.Condition
+		status    int
+		want      bool
+		wantErr   bool
+	}{
+		{"pending", &rctypes.Condition{Kind: rctypes.BiosControl, State: rctypes.Pending}, http.StatusOK, true, false},
+		{"active", &rctypes.Condition{Kind: rctypes.BiosControl, State: rctypes.Active}, http.StatusOK, true, false},
+		{"completed", &rctypes.Condition{Kind: rctypes.BiosControl, State: rctypes.Succeeded}, http.StatusOK, false, false},
+		{"other kind", &rctypes.Condition{Kind: rctypes.FirmwareInstall, State: rctypes.Pending}, http.StatusOK, false, false},
+		{"no condition", nil, http.StatusNotFound, false, false},
+		{"server error", nil, http.StatusInternalServerError, false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				_ = json.NewEncoder(w).Encode(map[string]any{"condition": tc.condition})
+			}))
+			defer srv.Close()
+
+			client, err := newOrchestratorClient(context.Background(), &config.Orchestrator{URL: srv.URL})
+			require.NoError(t, err)
+
+			l := &orchestratorListener{client: client}
+
+			queued, err := l.conditionQueued(context.Background(), serverID)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, errConditionQuery)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, queued)
+		})
+	}
+}
+
+func TestOrchestratorRunPanic(t *testing.T) {
+	l := &orchestratorListener{logger: logrus.NewEntry(logrus.New())}
+
+	// the zero controller panics dereferencing its nil logger
+	err := l.run(context.Background(), &ctrl.HTTPController{}, nil)
+	assert.ErrorIs(t, err, errControllerPanic)
+}
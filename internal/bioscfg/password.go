@@ -0,0 +1,56 @@
+package bioscfg
+
+import (
+	"context"
+	"time"
+)
+
+// setBiosPassword sets or changes the BIOS setup password to the password stored in fleetdb,
+// the password values are never logged or published in the condition status.
+func (th *TaskHandler) setBiosPassword(ctx context.Context) error {
+	password, err := th.fleetdb.BiosPassword(ctx, th.server.ID)
+	if err != nil {
+		return th.failedWithError(ctx, "error getting bios password", err)
+	}
+
+	if password.New == password.Current {
+		return th.successful(ctx, "bios password already set")
+	}
+
+	knownJobs, err := th.biosJobIDs(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
+	err = th.write(ctx, "bios password set", func(ctx context.Context) error {
+		return th.bmcClient.SetBiosPassword(ctx, password.Current, password.New)
+	})
+	if err != nil {
+		return th.failedWithError(ctx, "failed to set bios password through the bmc", err)
+	}
+
+	err = th.publishActive(ctx, "bios password change submitted")
+	if err != nil {
+		return err
+	}
+
+	job, err := th.createdBiosJob(ctx, knownJobs)
+	if err != nil {
+		return th.failedWithError(ctx, "error listing bios jobs", err)
+	}
+
+	// the password is recorded once applied, the next rotation depends on the current password
+	if job != nil {
+		job, err = th.awaitBiosJob(ctx, job, false)
+		if err != nil {
+			return th.biosJobFailed(ctx, job, err)
+		}
+	}
+
+	err = th.fleetdb.BiosPasswordRotated(ctx, th.server.ID, password.New, time.Now())
+	if err != nil {
+		return th.failedWithError(ctx, "bios password set, error recording the rotation", err)
+	}
+
+	return th.successful(ctx, "bios password set")
+}
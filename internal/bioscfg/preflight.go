@@ -0,0 +1,104 @@
+package bioscfg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+)
+
+// actionFeatures are the bmclib provider features required by each action
+var actionFeatures = map[rctypes.BiosControlAction][]bmc.Feature{
+	rctypes.ResetConfig: {bmc.FeaturePowerState, bmc.FeatureResetBiosConfig, bmc.FeaturePowerSet},
+	rctypes.SetConfig:   {bmc.FeatureSetBiosConfigFromFile},
+	GetConfig:           {bmc.FeatureGetBiosConfig},
+	PendingConfig:       {},
+	SecureBootStatus:    {},
+	SecureBootEnable:    {},
+	SecureBootDisable:   {},
+	SecureBootResetKeys: {},
+	SetBiosPassword:     {},
+	SetBootMode:         {},
+}
+
+// readOnlyActions make no change to the server, and are not blocked by unfinished BIOS jobs
+var readOnlyActions = map[rctypes.BiosControlAction]bool{
+	GetConfig:        true,
+	PendingConfig:    true,
+	SecureBootStatus: true,
+}
+
+// localActions are only run from the command line, get_config reports all the BIOS settings,
+// too large a status for the condition status record.
+var localActions = map[rctypes.BiosControlAction]bool{
+	GetConfig: true,
+}
+
+// preflight verifies the BMC is able to complete the action before any change is made to the server,
+// it expects the BMC session to be open, which validates the BMC is reachable and the credentials.
+func (th *TaskHandler) preflight(ctx context.Context) error {
+	report := []string{"bmc reachable", "credentials valid"}
+
+	features, ok := actionFeatures[th.task.Parameters.Action]
+	if !ok {
+		return errors.Wrap(errUnsupportedAction, string(th.task.Parameters.Action))
+	}
+
+	if localActions[th.task.Parameters.Action] && !th.local {
+		return errors.Wrap(errUnsupportedAction, string(th.task.Parameters.Action)+" is only run from the command line")
+	}
+
+	if err := th.checkCapabilities(ctx, true); err != nil {
+		return err
+	}
+
+	for _, feature := range features {
+		providers := th.bmcClient.SupportedProviders(feature)
+		if len(providers) == 0 {
+			return errors.Wrap(errPreflight, "no bmc provider supports: "+string(feature))
+		}
+
+		report = append(report, fmt.Sprintf("%s: %s", feature, strings.Join(providers, ",")))
+	}
+
+	if !readOnlyActions[th.task.Parameters.Action] {
+		jobsReport, err := th.preflightBiosJobs(ctx)
+		if err != nil {
+			return err
+		}
+
+		report = append(report, jobsReport)
+	}
+
+	state, err := th.bmcClient.GetPowerState(ctx)
+	if err != nil {
+		return errors.Wrap(errPreflight, "error getting power state: "+err.Error())
+	}
+
+	report = append(report, "power state: "+string(state))
+
+	return th.publishActive(ctx, "pre-flight checks passed: "+strings.Join(report, "; "))
+}
+
+// preflightBiosJobs fails when a BIOS job is still to be run, the new settings could be overridden by the job
+func (th *TaskHandler) preflightBiosJobs(ctx context.Context) (string, error) {
+	jobs, err := th.bmcClient.BiosJobs(ctx)
+	switch {
+	case bmc.ErrorClassOf(err) == bmc.ErrorClassUnsupported:
+		return "pending bios jobs: not supported by bmc", nil
+	case err != nil:
+		return "", errors.Wrap(errPreflight, "error listing bios jobs: "+err.Error())
+	}
+
+	for _, job := range jobs {
+		if !job.Finished() {
+			return "", errors.Wrap(errPreflight, fmt.Sprintf("bios job %s is %s", job.ID, job.State))
+		}
+	}
+
+	return "pending bios jobs: none", nil
+}
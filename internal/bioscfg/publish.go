@@ -3,6 +3,7 @@ package bioscfg
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
@@ -22,14 +23,7 @@ func (th *TaskHandler) publish(ctx context.Context, status string, state rctypes
 		return err
 	}
 
-	if errDelay := sleepInContext(ctx, 10*time.Second); errDelay != nil {
-		return context.Canceled
-	}
-
-	return th.publisher.Publish(ctx,
-		genTask,
-		false,
-	)
+	return th.publisher.Publish(ctx, genTask)
 }
 
 func (th *TaskHandler) publishActive(ctx context.Context, status string) error {
@@ -51,8 +45,22 @@ func (th *TaskHandler) publishActivef(ctx context.Context, status string, args .
 	return th.publishActive(ctx, status)
 }
 
-// failed condition helper method
+// failed condition helper method, the exceeded deadline or the cancellation is named in the status,
+// along with the changes already applied.
 func (th *TaskHandler) failed(ctx context.Context, status string) error {
+	if cause := stopCause(ctx); cause != nil {
+		if !strings.Contains(status, cause.Error()) {
+			status = cause.Error() + ": " + status
+		}
+
+		status += "; " + th.appliedStatus()
+
+		// the context is cancelled, the failed status is still to be published
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), deadlineReportTimeout)
+		defer cancel()
+	}
+
 	err := th.publish(ctx, status, rctypes.Failed)
 
 	th.registerConditionMetrics(string(rctypes.Failed))
@@ -99,16 +107,7 @@ func (th *TaskHandler) registerConditionMetrics(status string) {
 		prometheus.Labels{
 			"condition": string(rctypes.ServerControl),
 			"state":     status,
+			"facility":  th.facility,
 		},
 	).Observe(time.Since(th.startTS).Seconds())
 }
-
-// sleepInContext
-func sleepInContext(ctx context.Context, t time.Duration) error {
-	select {
-	case <-time.After(t):
-		return nil
-	case <-ctx.Done():
-		return context.Canceled
-	}
-}
@@ -0,0 +1,166 @@
+package bioscfg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+)
+
+// rebootIfOn resets the server when it is powered on, false is returned when the server is off.
+func (th *TaskHandler) rebootIfOn(ctx context.Context) (bool, error) {
+	state, err := th.bmcClient.GetPowerState(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if state != model.PowerStateOn {
+		return false, nil
+	}
+
+	return true, th.reboot(ctx, model.PowerActionReset, nil)
+}
+
+// reboot runs the reset, or on, power action and waits for the host to boot, within the reboot step deadline,
+// job is the BIOS job to be run by the reboot, nil when there is none.
+func (th *TaskHandler) reboot(ctx context.Context, action model.PowerAction, job *bmc.Job) error {
+	ctx, cancel := th.stepDeadline(ctx, stepReboot)
+	defer cancel()
+
+	err := th.write(ctx, "server power "+string(action), func(ctx context.Context) error {
+		return th.bmcClient.SetPowerState(ctx, action)
+	})
+	if err != nil {
+		return stepError(ctx, err)
+	}
+
+	if err := th.publishActive(ctx, "server power "+string(action)+", waiting for host to boot"); err != nil {
+		return stepError(ctx, err)
+	}
+
+	return stepError(ctx, th.waitHostBooted(ctx, job))
+}
+
+// waitHostBooted samples the host POST code until the OS is booted, POST state transitions are published,
+// the host is considered stuck when the POST code is unchanged for the configured stuck timeout.
+func (th *TaskHandler) waitHostBooted(ctx context.Context, job *bmc.Job) error {
+	cfg := th.cfg.BootWait
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	var last *bmc.BootProgress
+	lastChange := time.Now()
+	// the POST code may read as booted before the reset takes effect, or through the reset on some BMCs
+	reset := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			if last == nil {
+				return errors.Wrap(errHostBootTimeout, "no post code read")
+			}
+
+			return errors.Wrap(errHostBootTimeout, "last "+last.String())
+		case <-ticker.C:
+		}
+
+		progress, err := th.bmcClient.BootProgress(ctx)
+		if err != nil {
+			if bmc.ErrorClassOf(err) == bmc.ErrorClassUnsupported {
+				return th.publishActive(ctx, "boot progress not supported by bmc, not waiting for host to boot")
+			}
+
+			th.logger.WithError(err).Warn("error reading boot progress")
+			continue
+		}
+
+		if last == nil || *progress != *last {
+			lastChange = time.Now()
+
+			if last == nil || progress.State != last.State {
+				if err := th.publishActive(ctx, "boot progress: "+progress.String()); err != nil {
+					return err
+				}
+			} else {
+				th.logger.Debug("boot progress: " + progress.String())
+			}
+		}
+
+		last = progress
+		unchanged := time.Since(lastChange) >= cfg.StuckTimeout
+
+		if !progress.Booted() || (!reset && th.resetObserved(ctx, job)) {
+			reset = true
+		}
+
+		switch {
+		case progress.Booted() && (reset || unchanged):
+			return th.publishActive(ctx, "host booted")
+		case progress.Booted():
+		case unchanged:
+			return errors.Wrap(errHostStuck, fmt.Sprintf("%s for %s", progress, cfg.StuckTimeout))
+		}
+	}
+}
+
+// resetObserved returns true when the server is seen powering on, or the BIOS job run by the reboot has started.
+func (th *TaskHandler) resetObserved(ctx context.Context, job *bmc.Job) bool {
+	state, err := th.bmcClient.GetPowerState(ctx)
+	if err == nil && state != model.PowerStateOn {
+		return true
+	}
+
+	if job == nil {
+		return false
+	}
+
+	current, err := th.biosJob(ctx, job.ID)
+
+	return err == nil && current.State != bmc.JobStatePending
+}
+
+// waitVerified polls verify until the change is observed on the server, or the configured timeout is reached,
+// within the verify step deadline.
+func (th *TaskHandler) waitVerified(ctx context.Context, change string, verify func(context.Context) (bool, error)) error {
+	cfg := th.cfg.BiosJobs
+
+	stepCtx, cancelStep := th.stepDeadline(ctx, stepVerify)
+	defer cancelStep()
+
+	ctx, cancel := context.WithTimeout(stepCtx, cfg.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := stopCause(stepCtx); err != nil {
+				return errors.Wrap(err, "verifying "+change)
+			}
+
+			return errors.Wrap(errVerifyTimeout, fmt.Sprintf("%s, after %s", change, cfg.Timeout))
+		case <-ticker.C:
+		}
+
+		ok, err := verify(ctx)
+		if err != nil {
+			// the BMC may be unresponsive while the server reboots, keep polling until the timeout
+			th.logger.WithError(err).Warn("error verifying " + change)
+			continue
+		}
+
+		if ok {
+			return nil
+		}
+	}
+}
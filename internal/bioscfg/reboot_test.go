@@ -0,0 +1,109 @@
+package bioscfg
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/bmclib/constants"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/bioscfg/internal/config"
+	"github.com/metal-toolbox/bioscfg/internal/model"
+	"github.com/metal-toolbox/bioscfg/internal/publisher"
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+)
+
+// osPostBMC reports the OS POST state through the reset, the reset is observed from the power state or the BIOS job.
+type osPostBMC struct {
+	*bmc.DryRunBMCClient
+	polls      int
+	powerState func(polls int) model.PowerState
+	jobState   func(polls int) bmc.JobState
+}
+
+func (b *osPostBMC) BootProgress(context.Context) (*bmc.BootProgress, error) {
+	b.polls++
+	return &bmc.BootProgress{State: constants.POSTStateOS}, nil
+}
+
+func (b *osPostBMC) GetPowerState(context.Context) (model.PowerState, error) {
+	return b.powerState(b.polls), nil
+}
+
+func (b *osPostBMC) BiosJobs(context.Context) ([]*bmc.Job, error) {
+	return []*bmc.Job{{ID: "JID_1", State: b.jobState(b.polls)}}, nil
+}
+
+func TestWaitHostBooted(t *testing.T) {
+	poweredOn := func(int) model.PowerState { return model.PowerStateOn }
+	pending := func(int) bmc.JobState { return bmc.JobStatePending }
+
+	cases := []struct {
+		name       string
+		powerState func(int) model.PowerState
+		jobState   func(int) bmc.JobState
+		job        *bmc.Job
+		expectErr  bool
+	}{
+		{
+			"power transition",
+			func(polls int) model.PowerState {
+				if polls == 2 {
+					return model.PowerStatePoweringOn
+				}
+				return model.PowerStateOn
+			},
+			pending,
+			nil,
+			false,
+		},
+		{
+			"bios job started",
+			poweredOn,
+			func(polls int) bmc.JobState {
+				if polls >= 3 {
+					return bmc.JobStateRunning
+				}
+				return bmc.JobStatePending
+			},
+			&bmc.Job{ID: "JID_1", State: bmc.JobStatePending},
+			false,
+		},
+		{"reset not observed", poweredOn, pending, &bmc.Job{ID: "JID_1", State: bmc.JobStatePending}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := logrus.NewEntry(logrus.New())
+			statusPublisher, err := publisher.New(publisher.NewConsole(io.Discard), nil, logger)
+			require.NoError(t, err)
+
+			th := &TaskHandler{
+				cfg: &config.Configuration{
+					BootWait: config.BootWait{Timeout: 200 * time.Millisecond, PollInterval: time.Millisecond, StuckTimeout: time.Hour},
+				},
+				logger:    logger,
+				publisher: statusPublisher,
+				task:      &Task{},
+				bmcClient: &osPostBMC{
+					DryRunBMCClient: bmc.NewDryRunBMCClient(&model.Asset{ID: uuid.New()}),
+					powerState:      tc.powerState,
+					jobState:        tc.jobState,
+				},
+			}
+
+			err = th.waitHostBooted(context.Background(), tc.job)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, errHostBootTimeout)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package bioscfg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+)
+
+var errReplay = errors.New("invalid condition to replay")
+
+// Replay runs a BiosControl condition task, as published by the orchestrator, with the condition handler, without NATS.
+// The task state and status are reset, the status published by the handler is printed to out,
+// the BMC is simulated when dryrun is set, the server is otherwise looked up in fleetdb, unless given in target.
+func Replay(ctx context.Context, configFile, logLevel, taskFile string, target *LocalTarget, dryrun bool, out io.Writer) error {
+	cfg, logger, err := loadLocalConfig(configFile, logLevel)
+	if err != nil {
+		return err
+	}
+
+	if dryrun {
+		cfg.Dryrun = true
+	}
+
+	task, err := loadTask(taskFile)
+	if err != nil {
+		return err
+	}
+
+	// validates the parameters as the handler does
+	parsed, err := newTask(task)
+	if err != nil {
+		return errors.Wrap(errReplay, err.Error())
+	}
+
+	assetID := parsed.Parameters.AssetID.String()
+	if target.ServerID != "" && target.ServerID != assetID {
+		return errors.Wrap(errReplay, "--server differs from the condition asset_id "+assetID)
+	}
+
+	target.ServerID = assetID
+
+	store, _, err := localInventory(ctx, cfg, target, logger)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "replaying condition %s, action: %s, state was: %s\n", task.ID, parsed.Parameters.Action, task.State)
+
+	task.State = rctypes.Pending
+	task.Status = rctypes.StatusRecord{}
+	task.WorkerID = localControllerID
+
+	return runLocalTask(ctx, cfg, logger, store, task, out)
+}
+
+// loadTask reads a BiosControl condition task from a JSON file.
+func loadTask(path string) (*rctypes.Task[any, any], error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(errReplay, err.Error())
+	}
+
+	// the parameters are kept as JSON, as they are received from NATS
+	raw := &rctypes.Task[json.RawMessage, json.RawMessage]{}
+	if err := json.Unmarshal(b, raw); err != nil {
+		return nil, errors.Wrap(errReplay, err.Error())
+	}
+
+	if raw.Kind != rctypes.BiosControl {
+		return nil, errors.Wrap(errReplay, "condition kind: "+string(raw.Kind))
+	}
+
+	if len(raw.Parameters) == 0 {
+		return nil, errors.Wrap(errReplay, "no condition parameters")
+	}
+
+	return &rctypes.Task[any, any]{
+		StructVersion: raw.StructVersion,
+		ID:            raw.ID,
+		Kind:          raw.Kind,
+		State:         raw.State,
+		Status:        raw.Status,
+		Data:          raw.Data,
+		Parameters:    raw.Parameters,
+		Fault:         raw.Fault,
+		FacilityCode:  raw.FacilityCode,
+		Server:        raw.Server,
+		WorkerID:      raw.WorkerID,
+		TraceID:       raw.TraceID,
+		SpanID:        raw.SpanID,
+		CreatedAt:     raw.CreatedAt,
+		UpdatedAt:     raw.UpdatedAt,
+		CompletedAt:   raw.CompletedAt,
+	}, nil
+}
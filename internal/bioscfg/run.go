@@ -2,6 +2,10 @@ package bioscfg
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/equinix-labs/otel-init-go/otelinit"
 	"github.com/metal-toolbox/bioscfg/internal/config"
@@ -12,6 +16,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// time to export the pending telemetry on shutdown.
+const otelShutdownTimeout = 10 * time.Second
+
+// Run runs the controller until SIGINT or SIGTERM is received, in-flight conditions are then drained.
 func Run(ctx context.Context, configFile, logLevel string, enableProfiling bool) error {
 	cfg, err := config.Load(configFile, logLevel)
 	if err != nil {
@@ -25,14 +33,20 @@ func Run(ctx context.Context, configFile, logLevel string, enableProfiling bool)
 		return err
 	}
 
-	metrics.ListenAndServe()
+	metrics.ListenAndServe(cfg.Listen.Metrics)
 	version.ExportBuildInfoMetric()
 	if enableProfiling {
-		profiling.Enable()
+		profiling.Enable(cfg.Listen.Profiling)
 	}
 
 	ctx, otelShutdown := otelinit.InitOpenTelemetry(ctx, model.Name)
-	defer otelShutdown(ctx)
+	defer func() {
+		// ctx may be cancelled by then, the spans are flushed with a bounded timeout
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), otelShutdownTimeout)
+		defer cancel()
+
+		otelShutdown(flushCtx)
+	}()
 
 	v, err := version.Current().AsMap()
 	if err != nil {
@@ -46,7 +60,13 @@ func Run(ctx context.Context, configFile, logLevel string, enableProfiling bool)
 		return err
 	}
 
+	controller.Health().ListenAndServe(cfg.Listen.Health)
+
+	// the controller liveness runs with ctx, to be kept alive while the conditions drain
+	listenCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	loggerEntry.Infof("Success! %s is starting to listen for conditions", model.Name)
 
-	return controller.Listen(ctx)
+	return controller.Listen(listenCtx)
 }
@@ -0,0 +1,132 @@
+package bioscfg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+)
+
+const secureBootSetupMode = "SetupMode"
+
+// secureBootStatus reports the UEFI Secure Boot state
+func (th *TaskHandler) secureBootStatus(ctx context.Context) error {
+	state, err := th.bmcClient.GetSecureBoot(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error getting secure boot state", err)
+	}
+
+	return th.successful(ctx, "secure boot "+formatSecureBoot(state))
+}
+
+// setSecureBoot enables or disables UEFI Secure Boot, and verifies the change after a reboot
+func (th *TaskHandler) setSecureBoot(ctx context.Context, enable bool) error {
+	if !enable && !th.task.Parameters.AllowSecureBootDisable {
+		return th.failedWithError(ctx, "refusing to disable secure boot",
+			errors.Wrap(errOverrideRequired, "allow_secure_boot_disable"))
+	}
+
+	state, err := th.bmcClient.GetSecureBoot(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "error getting secure boot state", err)
+	}
+
+	err = th.publishActive(ctx, "current secure boot "+formatSecureBoot(state))
+	if err != nil {
+		return err
+	}
+
+	if state.Enabled == enable && state.CurrentBoot == enable {
+		return th.successful(ctx, "secure boot already "+enabledString(enable))
+	}
+
+	if state.Enabled != enable {
+		err = th.write(ctx, "secure boot "+enabledString(enable), func(ctx context.Context) error {
+			return th.bmcClient.SetSecureBoot(ctx, enable)
+		})
+		if err != nil {
+			return th.failedWithError(ctx, "error setting secure boot", err)
+		}
+
+		err = th.publishActive(ctx, "secure boot set to "+enabledString(enable)+", applied on next boot")
+		if err != nil {
+			return err
+		}
+	}
+
+	rebooted, err := th.rebootIfOn(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "failed to reboot server", err)
+	}
+
+	if !rebooted {
+		return th.successful(ctx, "skipping server reboot, not on, secure boot "+enabledString(enable)+" on next boot")
+	}
+
+	err = th.waitVerified(ctx, "secure boot "+enabledString(enable), func(ctx context.Context) (bool, error) {
+		state, err = th.bmcClient.GetSecureBoot(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		return state.CurrentBoot == enable, nil
+	})
+	if err != nil {
+		return th.failedWithError(ctx, "secure boot not verified", err)
+	}
+
+	return th.successful(ctx, "secure boot verified "+formatSecureBoot(state))
+}
+
+// resetSecureBootKeys resets the UEFI Secure Boot key databases to their defaults
+func (th *TaskHandler) resetSecureBootKeys(ctx context.Context) error {
+	err := th.write(ctx, "secure boot keys reset", th.bmcClient.ResetSecureBootKeys)
+	if err != nil {
+		return th.failedWithError(ctx, "error resetting secure boot keys", err)
+	}
+
+	err = th.publishActive(ctx, "secure boot keys reset to defaults")
+	if err != nil {
+		return err
+	}
+
+	rebooted, err := th.rebootIfOn(ctx)
+	if err != nil {
+		return th.failedWithError(ctx, "failed to reboot server", err)
+	}
+
+	if !rebooted {
+		return th.successful(ctx, "skipping server reboot, not on")
+	}
+
+	// without a platform key the firmware remains in setup mode
+	var state *bmc.SecureBoot
+	err = th.waitVerified(ctx, "secure boot keys", func(ctx context.Context) (bool, error) {
+		state, err = th.bmcClient.GetSecureBoot(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		return state.Mode != "" && state.Mode != secureBootSetupMode, nil
+	})
+	if err != nil {
+		return th.failedWithError(ctx, "secure boot keys not verified", err)
+	}
+
+	return th.successful(ctx, "secure boot keys verified, "+formatSecureBoot(state))
+}
+
+func formatSecureBoot(state *bmc.SecureBoot) string {
+	return fmt.Sprintf("enabled: %t, current boot: %s, mode: %s",
+		state.Enabled, enabledString(state.CurrentBoot), state.Mode)
+}
+
+func enabledString(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+
+	return "disabled"
+}
@@ -0,0 +1,152 @@
+package bioscfg
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// time for aborted handlers to publish the interrupted status and close the BMC session.
+	shutdownAbortTimeout = 30 * time.Second
+	drainPollInterval    = 100 * time.Millisecond
+)
+
+// shutdown coordinates a controller shutdown with the in-flight task handlers.
+//
+// Once the shutdown begins, handlers stop at the next checkpoint, handlers past their last checkpoint
+// are left to complete within the grace period, after which their context is cancelled.
+type shutdown struct {
+	// stopping is closed when the shutdown begins.
+	stopping chan struct{}
+	once     sync.Once
+	// abortCtx is cancelled when the grace period expires.
+	abortCtx context.Context
+	abort    context.CancelFunc
+	active   atomic.Int32
+}
+
+func newShutdown() *shutdown {
+	abortCtx, abort := context.WithCancel(context.Background())
+
+	return &shutdown{
+		stopping: make(chan struct{}),
+		abortCtx: abortCtx,
+		abort:    abort,
+	}
+}
+
+// begin starts the shutdown, handlers are interrupted at their next checkpoint.
+func (s *shutdown) begin() {
+	s.once.Do(func() { close(s.stopping) })
+}
+
+func (s *shutdown) isStopping() bool {
+	if s == nil {
+		return false
+	}
+
+	select {
+	case <-s.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *shutdown) aborted() bool {
+	return s != nil && s.abortCtx.Err() != nil
+}
+
+// handlerContext returns the context to run a handler with, it is detached from the listener context
+// which is cancelled on shutdown, keeps its deadline, and is cancelled when the grace period expires.
+func (s *shutdown) handlerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s == nil {
+		return context.WithCancel(ctx)
+	}
+
+	s.active.Add(1)
+
+	hctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if deadline, ok := ctx.Deadline(); ok {
+		hctx, cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	}
+
+	stop := context.AfterFunc(s.abortCtx, cancel)
+
+	return hctx, func() {
+		stop()
+		cancel()
+		s.active.Add(-1)
+	}
+}
+
+// drain waits for the in-flight handlers to return within the grace period,
+// the handlers still running are then aborted, and given a short time to report the interruption.
+func (s *shutdown) drain(grace time.Duration, logger *logrus.Entry) {
+	s.begin()
+
+	if !s.wait(grace) {
+		logger.WithField("handlers", s.active.Load()).Warn("shutdown grace period expired, aborting in-flight conditions")
+
+		s.abort()
+		s.wait(shutdownAbortTimeout)
+	}
+
+	s.abort()
+}
+
+func (s *shutdown) wait(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	for s.active.Load() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(drainPollInterval)
+	}
+
+	return true
+}
+
+// checkpoint returns errInterrupted when the controller is shutting down,
+// it is called where the handler is able to stop without leaving a change partially applied.
+func (th *TaskHandler) checkpoint() error {
+	if th.shutdown.isStopping() {
+		return errInterrupted
+	}
+
+	return nil
+}
+
+// reportInterrupted publishes the interrupted status of a condition stopped at a checkpoint,
+// or aborted after the shutdown grace period, the condition is not redelivered and is to be retried.
+func (th *TaskHandler) reportInterrupted(ctx context.Context, err error) {
+	stopped := errors.Is(err, errInterrupted)
+	if !stopped && !th.shutdown.aborted() {
+		return
+	}
+
+	if th.task == nil || th.task.State == rctypes.Succeeded {
+		return
+	}
+
+	// the handler context is cancelled when aborted
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownAbortTimeout)
+	defer cancel()
+
+	status := "interrupted by controller shutdown, before any change was made"
+	if !stopped {
+		status = "interrupted by controller shutdown, the action may be partially applied"
+	}
+
+	if errPublish := th.failed(ctx, status); errPublish != nil {
+		th.logger.WithError(errPublish).Error("failed to publish interrupted condition status")
+	}
+}
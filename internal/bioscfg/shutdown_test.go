@@ -0,0 +1,86 @@
+package bioscfg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownCheckpoint(t *testing.T) {
+	th := &TaskHandler{}
+	assert.NoError(t, th.checkpoint(), "no shutdown coordinator")
+
+	th.shutdown = newShutdown()
+	assert.NoError(t, th.checkpoint())
+
+	th.shutdown.begin()
+	th.shutdown.begin()
+	assert.ErrorIs(t, th.checkpoint(), errInterrupted)
+	assert.False(t, th.shutdown.aborted())
+}
+
+func TestShutdownHandlerContext(t *testing.T) {
+	s := newShutdown()
+
+	deadline := time.Now().Add(time.Hour)
+	listenerCtx, cancelListener := context.WithDeadline(context.Background(), deadline)
+
+	ctx, done := s.handlerContext(listenerCtx)
+	assert.Equal(t, int32(1), s.active.Load())
+
+	got, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, got)
+
+	// the handler is detached from the listener context cancelled on shutdown
+	cancelListener()
+	assert.NoError(t, ctx.Err())
+
+	// and cancelled once aborted
+	s.abort()
+	assert.Eventually(t, func() bool { return ctx.Err() != nil }, time.Second, time.Millisecond)
+	assert.True(t, s.aborted())
+
+	done()
+	assert.Equal(t, int32(0), s.active.Load())
+}
+
+func TestShutdownDrain(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+
+	t.Run("handlers complete within the grace period", func(t *testing.T) {
+		s := newShutdown()
+		ctx, done := s.handlerContext(context.Background())
+
+		go func() {
+			<-s.stopping
+			done()
+		}()
+
+		s.drain(time.Minute, logger)
+		assert.Equal(t, int32(0), s.active.Load())
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+
+	t.Run("handlers aborted once the grace period expires", func(t *testing.T) {
+		s := newShutdown()
+		ctx, done := s.handlerContext(context.Background())
+
+		aborted := make(chan time.Time, 1)
+		go func() {
+			<-ctx.Done()
+			aborted <- time.Now()
+			done()
+		}()
+
+		start := time.Now()
+		s.drain(50*time.Millisecond, logger)
+
+		assert.GreaterOrEqual(t, (<-aborted).Sub(start), 50*time.Millisecond)
+		assert.Equal(t, int32(0), s.active.Load())
+		assert.True(t, s.aborted())
+	})
+}
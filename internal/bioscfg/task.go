@@ -2,6 +2,7 @@ package bioscfg
 
 import (
 	"encoding/json"
+	"slices"
 
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 	rtypes "github.com/metal-toolbox/rivets/v2/types"
@@ -9,7 +10,66 @@ import (
 	"github.com/pkg/errors"
 )
 
-type Task rctypes.Task[*rctypes.BiosControlTaskParameters, json.RawMessage]
+// BiosControl actions supported by bioscfg, in addition to the rctypes actions.
+const (
+	// GetConfig reports the current BIOS settings.
+	GetConfig rctypes.BiosControlAction = "get_config"
+
+	// PendingConfig reports the BIOS settings staged on the BMC, to be applied on the next boot.
+	PendingConfig rctypes.BiosControlAction = "pending_config"
+
+	// SecureBootStatus reports the UEFI Secure Boot state.
+	SecureBootStatus rctypes.BiosControlAction = "secure_boot_status"
+
+	// SecureBootEnable enables UEFI Secure Boot.
+	SecureBootEnable rctypes.BiosControlAction = "secure_boot_enable"
+
+	// SecureBootDisable disables UEFI Secure Boot, the AllowSecureBootDisable parameter is required.
+	SecureBootDisable rctypes.BiosControlAction = "secure_boot_disable"
+
+	// SecureBootResetKeys resets the UEFI Secure Boot key databases to their defaults.
+	SecureBootResetKeys rctypes.BiosControlAction = "secure_boot_reset_keys"
+
+	// SetBiosPassword sets or rotates the BIOS setup password to the password stored in fleetdb.
+	SetBiosPassword rctypes.BiosControlAction = "set_bios_password"
+
+	// SetBootMode converts the firmware boot mode to the BootMode parameter.
+	SetBootMode rctypes.BiosControlAction = "set_boot_mode"
+)
+
+// TaskParameters are the BiosControl condition parameters, with the options supported by bioscfg.
+type TaskParameters struct {
+	rctypes.BiosControlTaskParameters
+
+	// ClearPending discards the BIOS settings staged on the BMC before a set_config or reset_config action.
+	ClearPending bool `json:"clear_pending,omitempty"`
+
+	// AllowSecureBootDisable must be set for the secure_boot_disable action.
+	AllowSecureBootDisable bool `json:"allow_secure_boot_disable,omitempty"`
+
+	// BootMode is the firmware boot mode to set with the set_boot_mode action - uefi or legacy.
+	BootMode string `json:"boot_mode,omitempty"`
+
+	// BootDiskLayout is the partition table of the boot disk - gpt or mbr,
+	// the boot mode is not changed when unknown or incompatible, unless forced.
+	BootDiskLayout string `json:"boot_disk_layout,omitempty"`
+
+	// Force the boot mode change when the boot disk layout is unknown or incompatible.
+	Force bool `json:"force,omitempty"`
+
+	// Deadline overrides the configured task deadline - e.g. "90m", within the configured maximum.
+	Deadline string `json:"deadline,omitempty"`
+
+	// StepDeadlines override the configured step deadlines, within the configured maximum.
+	StepDeadlines map[string]string `json:"step_deadlines,omitempty"`
+}
+
+// Marshal returns the JSON encoded parameters, the embedded rctypes Marshal method would drop the bioscfg options.
+func (p *TaskParameters) Marshal() (json.RawMessage, error) {
+	return json.Marshal(p)
+}
+
+type Task rctypes.Task[*TaskParameters, json.RawMessage]
 
 // newTask converts a Generic Condition Task to a BiosControl Task
 func newTask(task *rctypes.Task[any, any]) (*Task, error) {
@@ -18,7 +78,7 @@ func newTask(task *rctypes.Task[any, any]) (*Task, error) {
 		return nil, errInvalidConditionParams
 	}
 
-	params := rctypes.BiosControlTaskParameters{}
+	params := TaskParameters{}
 	if err := json.Unmarshal(paramsJSON, &params); err != nil {
 		return nil, err
 	}
@@ -76,7 +136,7 @@ func (task *Task) toGeneric() (*rctypes.Task[any, any], error) {
 		ID:            task.ID,
 		Kind:          task.Kind,
 		State:         task.State,
-		Status:        task.Status,
+		Status:        rctypes.StatusRecord{StatusMsgs: slices.Clone(task.Status.StatusMsgs)},
 		Parameters:    paramsJSON,
 		Fault:         fault.(*rctypes.Fault),
 		FacilityCode:  task.FacilityCode,
@@ -0,0 +1,191 @@
+// Package biosconfig parses vendor BIOS config files into a normalized attribute map,
+// to compare them and validate them against a recorded attribute registry, without a BMC.
+package biosconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html/charset"
+)
+
+// Format is a BIOS config file format.
+type Format string
+
+const (
+	// FormatRedfish is a JSON map of Redfish BIOS attributes, or a Redfish Bios resource.
+	FormatRedfish Format = "redfish"
+
+	// FormatDellSCPXML is a Dell Server Configuration Profile in XML.
+	FormatDellSCPXML Format = "dell_scp_xml"
+
+	// FormatDellSCPJSON is a Dell Server Configuration Profile in JSON.
+	FormatDellSCPJSON Format = "dell_scp_json"
+
+	// FormatSupermicroXML is a Supermicro Update Manager BIOS config in XML.
+	FormatSupermicroXML Format = "supermicro_xml"
+
+	// dellBiosFQDD is the Dell SCP component holding the BIOS attributes, named as the Redfish attributes.
+	dellBiosFQDD = "BIOS.Setup.1-1"
+
+	// MenuSeparator separates the Supermicro menu path elements in an attribute name.
+	MenuSeparator = " > "
+)
+
+var (
+	ErrParse  = errors.New("error parsing bios config")
+	ErrFormat = errors.New("unknown bios config format")
+)
+
+// Attributes are the BIOS attributes of a config file, by name,
+// values are normalized to be compared regardless of the whitespace in the file.
+type Attributes map[string]string
+
+func (a Attributes) set(name, value string) {
+	a[strings.TrimSpace(name)] = normalizeValue(value)
+}
+
+func normalizeValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// ParseFile parses the BIOS config file.
+func ParseFile(path string) (Attributes, Format, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", errors.Wrap(ErrParse, err.Error())
+	}
+
+	attrs, format, err := Parse(data)
+	if err != nil {
+		return nil, "", errors.Wrap(err, path)
+	}
+
+	return attrs, format, nil
+}
+
+// Parse parses a BIOS config in any of the supported formats, the format is detected from the content.
+func Parse(data []byte) (Attributes, Format, error) {
+	data = bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		return parseJSON(data)
+	}
+
+	// tools prefix the XML with a banner
+	start := bytes.IndexByte(data, '<')
+	if start < 0 {
+		return nil, "", ErrFormat
+	}
+
+	return parseXML(data[start:])
+}
+
+func parseJSON(data []byte) (Attributes, Format, error) {
+	doc := map[string]json.RawMessage{}
+	if err := unmarshalJSON(data, &doc); err != nil {
+		return nil, "", err
+	}
+
+	if scp, ok := doc["SystemConfiguration"]; ok {
+		attrs, err := parseDellSCPJSON(scp)
+		return attrs, FormatDellSCPJSON, err
+	}
+
+	// a Redfish Bios resource
+	if raw, ok := doc["Attributes"]; ok {
+		doc = map[string]json.RawMessage{}
+		if err := unmarshalJSON(raw, &doc); err != nil {
+			return nil, "", err
+		}
+	}
+
+	attrs := Attributes{}
+	for name, raw := range doc {
+		value, err := jsonValue(raw)
+		if err != nil {
+			return nil, "", errors.Wrap(err, name)
+		}
+
+		attrs.set(name, value)
+	}
+
+	return attrs, FormatRedfish, nil
+}
+
+// jsonValue returns a JSON scalar as a string, numbers are kept as written.
+func jsonValue(raw json.RawMessage) (string, error) {
+	var value interface{}
+	if err := unmarshalJSON(raw, &value); err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number, bool:
+		return fmt.Sprint(v), nil
+	case nil:
+		return "", nil
+	default:
+		return "", errors.Wrap(ErrParse, "not a scalar value")
+	}
+}
+
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(v); err != nil {
+		return errors.Wrap(ErrParse, err.Error())
+	}
+
+	return nil
+}
+
+func parseXML(data []byte) (Attributes, Format, error) {
+	root, err := xmlRoot(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch root {
+	case "SystemConfiguration":
+		attrs, err := parseDellSCPXML(data)
+		return attrs, FormatDellSCPXML, err
+	case "BiosCfg":
+		attrs, err := parseSupermicroXML(data)
+		return attrs, FormatSupermicroXML, err
+	default:
+		return nil, "", errors.Wrap(ErrFormat, "xml root element "+root)
+	}
+}
+
+// xmlRoot returns the name of the root element.
+func xmlRoot(data []byte) (string, error) {
+	dec := newXMLDecoder(data)
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return "", errors.Wrap(ErrParse, err.Error())
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func newXMLDecoder(data []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	// SUM files are ISO-8859-1 encoded
+	dec.CharsetReader = charset.NewReaderLabel
+
+	return dec
+}
@@ -0,0 +1,248 @@
+package biosconfig
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	dellSCPXML = `<SystemConfiguration Model="PowerEdge R6515" ServiceTag="ABC1234">
+  <Component FQDD="BIOS.Setup.1-1">
+    <Attribute Name="BootMode">Uefi</Attribute>
+    <Attribute Name="ProcVirtualization">  Enabled </Attribute>
+    <!-- <Attribute Name="SerialComm">OnConRedirCom1</Attribute> -->
+  </Component>
+  <Component FQDD="iDRAC.Embedded.1">
+    <Attribute Name="IPMILan.1#Enable">Enabled</Attribute>
+  </Component>
+</SystemConfiguration>`
+
+	dellSCPJSON = `{"SystemConfiguration": {"Model": "PowerEdge R6515", "Components": [
+  {"FQDD": "iDRAC.Embedded.1", "Attributes": [{"Name": "IPMILan.1#Enable", "Value": "Enabled"}]},
+  {"FQDD": "BIOS.Setup.1-1", "Attributes": [
+    {"Name": "ProcVirtualization", "Value": "Enabled"},
+    {"Name": "BootMode", "Value": "Uefi"}
+  ]}
+]}}`
+
+	supermicroXML = `Supermicro Update Manager (for UEFI BIOS) 2.14.0 (2024/02/15) (ARM64)
+.......................
+<?xml version="1.0" encoding="ISO-8859-1" standalone="yes"?>
+<BiosCfg>
+  <Menu name="Advanced">
+    <Menu name="Boot Feature">
+      <Setting name="Quiet Boot" checkedStatus="Checked" type="CheckBox"/>
+      <Setting name="Fast Boot" selectedOption="Disabled" type="Option">
+        <Information>
+          <AvailableOptions>
+            <Option value="0">Disabled</Option>
+            <Option value="1">Enabled</Option>
+          </AvailableOptions>
+        </Information>
+      </Setting>
+    </Menu>
+    <Menu name="PCIe/PCI/PnP Configuration">
+      <Setting name="  Power Limit Value" numericValue="75" type="Numeric">
+        <Information>
+          <MaxValue>255</MaxValue>
+          <MinValue>0</MinValue>
+        </Information>
+      </Setting>
+    </Menu>
+  </Menu>
+  <Menu name="Security">
+    <Setting name="Administrator Password" type="Password">
+      <NewPassword><![CDATA[]]></NewPassword>
+    </Setting>
+    <Setting name="Boot URI" type="String">
+      <Information>
+        <MinSize>0</MinSize>
+        <MaxSize>8</MaxSize>
+      </Information>
+      <StringValue><![CDATA[]]></StringValue>
+    </Setting>
+  </Menu>
+</BiosCfg>`
+
+	redfishRegistryJSON = `{"RegistryEntries": {"Attributes": [
+  {"AttributeName": "BootMode", "Type": "Enumeration", "Value": [{"ValueName": "Bios"}, {"ValueName": "Uefi"}]},
+  {"AttributeName": "ProcCores", "Type": "Integer", "LowerBound": 1, "UpperBound": 64},
+  {"AttributeName": "SystemServiceTag", "Type": "String", "ReadOnly": true},
+  {"AttributeName": "AssetTag", "Type": "String", "MaxLength": 4}
+]}}`
+)
+
+func TestParse(t *testing.T) {
+	dell := Attributes{
+		"BootMode":                          "Uefi",
+		"ProcVirtualization":                "Enabled",
+		"iDRAC.Embedded.1:IPMILan.1#Enable": "Enabled",
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		wantFormat Format
+		want       Attributes
+		wantErr    error
+	}{
+		{
+			"redfish attributes",
+			`{"BootMode": " Uefi", "ProcCores": 32, "Legacy": false}`,
+			FormatRedfish,
+			Attributes{"BootMode": "Uefi", "ProcCores": "32", "Legacy": "false"},
+			nil,
+		},
+		{
+			"redfish bios resource",
+			`{"@odata.id": "/redfish/v1/Systems/1/Bios", "Attributes": {"BootMode": "Uefi"}}`,
+			FormatRedfish,
+			Attributes{"BootMode": "Uefi"},
+			nil,
+		},
+		{"dell scp xml", dellSCPXML, FormatDellSCPXML, dell, nil},
+		{"dell scp json", dellSCPJSON, FormatDellSCPJSON, dell, nil},
+		{
+			"supermicro xml",
+			supermicroXML,
+			FormatSupermicroXML,
+			Attributes{
+				"Advanced > Boot Feature > Quiet Boot":                      "Checked",
+				"Advanced > Boot Feature > Fast Boot":                       "Disabled",
+				"Advanced > PCIe/PCI/PnP Configuration > Power Limit Value": "75",
+				"Security > Boot URI":                                       "",
+			},
+			nil,
+		},
+		{"unknown xml", `<Bios></Bios>`, "", nil, ErrFormat},
+		{"not a config", `BootMode=Uefi`, "", nil, ErrFormat},
+		{"nested json value", `{"BootMode": {"Value": "Uefi"}}`, "", nil, ErrParse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, format, err := Parse([]byte(tt.input))
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFormat, format)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	xml, _, err := Parse([]byte(dellSCPXML))
+	require.NoError(t, err)
+
+	json, _, err := Parse([]byte(dellSCPJSON))
+	require.NoError(t, err)
+
+	assert.Empty(t, Diff(xml, json), "same settings in another format and order")
+
+	changed := Attributes{"BootMode": "Bios", "ProcVirtualization": "Enabled", "SriovGlobalEnable": "Enabled"}
+	got := []string{}
+
+	for _, change := range Diff(xml, changed) {
+		got = append(got, change.String())
+	}
+
+	assert.Equal(t, []string{
+		`~ BootMode: "Uefi" -> "Bios"`,
+		`+ SriovGlobalEnable: "Enabled"`,
+		`- iDRAC.Embedded.1:IPMILan.1#Enable: "Enabled"`,
+	}, got)
+}
+
+func TestValidate(t *testing.T) {
+	redfish, err := ParseRegistry([]byte(redfishRegistryJSON))
+	require.NoError(t, err)
+
+	supermicro, err := ParseRegistry([]byte(supermicroXML))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		registry *Registry
+		attrs    Attributes
+		want     []string
+	}{
+		{
+			"valid",
+			redfish,
+			Attributes{"BootMode": "Uefi", "ProcCores": "8", "AssetTag": "a1"},
+			[]string{},
+		},
+		{
+			"invalid",
+			redfish,
+			Attributes{
+				"BootMode":         "Legacy",
+				"ProcCores":        "128",
+				"SystemServiceTag": "ABC1234",
+				"AssetTag":         "rack-42",
+				"Unknown":          "x",
+			},
+			[]string{
+				"AssetTag: length 7 out of bounds [-, 4]",
+				`BootMode: value "Legacy" not one of Bios, Uefi`,
+				"ProcCores: value 128 out of bounds [1, 64]",
+				"SystemServiceTag: read-only attribute",
+				"Unknown: unknown attribute",
+			},
+		},
+		{
+			"supermicro",
+			supermicro,
+			Attributes{
+				"Advanced > Boot Feature > Quiet Boot":                      "Unchecked",
+				"Advanced > Boot Feature > Fast Boot":                       "On",
+				"Advanced > PCIe/PCI/PnP Configuration > Power Limit Value": "300",
+				"Security > Boot URI":                                       "http://boot",
+			},
+			[]string{
+				`Advanced > Boot Feature > Fast Boot: value "On" not one of Disabled, Enabled`,
+				"Advanced > PCIe/PCI/PnP Configuration > Power Limit Value: value 300 out of bounds [0, 255]",
+				"Security > Boot URI: length 11 out of bounds [0, 8]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, problem := range tt.registry.Validate(tt.attrs) {
+				got = append(got, problem.String())
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSplitComponents(t *testing.T) {
+	registry, err := ParseRegistry([]byte(redfishRegistryJSON))
+	require.NoError(t, err)
+
+	attrs, format, err := Parse([]byte(dellSCPXML))
+	require.NoError(t, err)
+
+	bios, components := attrs.SplitComponents(format)
+	assert.Equal(t, Attributes{"BootMode": "Uefi", "ProcVirtualization": "Enabled"}, bios)
+	assert.Equal(t, Attributes{"iDRAC.Embedded.1:IPMILan.1#Enable": "Enabled"}, components)
+	assert.Equal(t, []string{"iDRAC.Embedded.1"}, components.Components())
+
+	// the registry lacks ProcVirtualization, the iDRAC attribute is not reported
+	assert.Equal(t, []Problem{{Name: "ProcVirtualization", Message: "unknown attribute"}}, registry.Validate(bios))
+
+	redfish := Attributes{"BootMode": "Uefi"}
+	bios, components = redfish.SplitComponents(FormatRedfish)
+	assert.Equal(t, redfish, bios)
+	assert.Empty(t, components)
+}
@@ -0,0 +1,128 @@
+package biosconfig
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ComponentSeparator separates the FQDD of a Dell component other than the BIOS from the attribute name.
+const ComponentSeparator = ":"
+
+// dellComponent is a component of a Dell Server Configuration Profile, components can be nested.
+type dellComponent struct {
+	FQDD       string          `xml:"FQDD,attr" json:"FQDD"`
+	Attributes []dellAttribute `xml:"Attribute" json:"Attributes"`
+	Components []dellComponent `xml:"Component" json:"Components"`
+}
+
+type dellAttribute struct {
+	Name  string          `xml:"Name,attr" json:"Name"`
+	Value string          `xml:",chardata" json:"-"`
+	Raw   json.RawMessage `xml:"-" json:"Value"`
+}
+
+type dellSCP struct {
+	Components []dellComponent `xml:"Component" json:"Components"`
+}
+
+func parseDellSCPXML(data []byte) (Attributes, error) {
+	scp := &dellSCP{}
+	if err := newXMLDecoder(data).Decode(scp); err != nil {
+		return nil, errors.Wrap(ErrParse, err.Error())
+	}
+
+	attrs := Attributes{}
+	for i := range scp.Components {
+		if err := scp.Components[i].attributes(attrs, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return attrs, nil
+}
+
+func parseDellSCPJSON(data []byte) (Attributes, error) {
+	scp := &dellSCP{}
+	if err := unmarshalJSON(data, scp); err != nil {
+		return nil, err
+	}
+
+	attrs := Attributes{}
+	for i := range scp.Components {
+		if err := scp.Components[i].attributes(attrs, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return attrs, nil
+}
+
+// attributes adds the component attributes, the BIOS attributes are named as in Redfish,
+// the attributes of the other components are prefixed by the component FQDD.
+func (c *dellComponent) attributes(attrs Attributes, fromJSON bool) error {
+	for _, attr := range c.Attributes {
+		value := attr.Value
+		if fromJSON {
+			var err error
+			if value, err = jsonValue(attr.Raw); err != nil {
+				return errors.Wrap(err, c.FQDD+" "+attr.Name)
+			}
+		}
+
+		name := attr.Name
+		if c.FQDD != dellBiosFQDD {
+			name = c.FQDD + ComponentSeparator + name
+		}
+
+		attrs.set(name, value)
+	}
+
+	for i := range c.Components {
+		if err := c.Components[i].attributes(attrs, fromJSON); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SplitComponents returns the BIOS attributes of a config in the format, and the attributes of the other components
+// of a Dell Server Configuration Profile, which are not described by a BIOS attribute registry.
+func (a Attributes) SplitComponents(format Format) (bios, components Attributes) {
+	if format != FormatDellSCPXML && format != FormatDellSCPJSON {
+		return a, Attributes{}
+	}
+
+	bios, components = Attributes{}, Attributes{}
+	for name, value := range a {
+		if strings.Contains(name, ComponentSeparator) {
+			components[name] = value
+			continue
+		}
+
+		bios[name] = value
+	}
+
+	return bios, components
+}
+
+// Components returns the sorted FQDDs of the component attributes.
+func (a Attributes) Components() []string {
+	fqdds := []string{}
+	seen := map[string]bool{}
+
+	for name := range a {
+		fqdd, _, _ := strings.Cut(name, ComponentSeparator)
+		if !seen[fqdd] {
+			seen[fqdd] = true
+			fqdds = append(fqdds, fqdd)
+		}
+	}
+
+	sort.Strings(fqdds)
+
+	return fqdds
+}
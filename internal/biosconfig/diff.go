@@ -0,0 +1,53 @@
+package biosconfig
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Change is a difference of an attribute between two configs,
+// an attribute missing from one of the configs has no value in that config.
+type Change struct {
+	Name     string
+	From, To *string
+}
+
+func (c *Change) String() string {
+	switch {
+	case c.From == nil:
+		return fmt.Sprintf("+ %s: %q", c.Name, *c.To)
+	case c.To == nil:
+		return fmt.Sprintf("- %s: %q", c.Name, *c.From)
+	default:
+		return fmt.Sprintf("~ %s: %q -> %q", c.Name, *c.From, *c.To)
+	}
+}
+
+// Diff returns the attributes changed from a to b, sorted by name.
+func Diff(a, b Attributes) []Change {
+	changes := []Change{}
+
+	for name, from := range a {
+		from := from
+
+		to, ok := b[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Name: name, From: &from})
+		case to != from:
+			changes = append(changes, Change{Name: name, From: &from, To: &to})
+		}
+	}
+
+	for name, to := range b {
+		to := to
+
+		if _, ok := a[name]; !ok {
+			changes = append(changes, Change{Name: name, To: &to})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+
+	return changes
+}
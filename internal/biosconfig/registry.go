@@ -0,0 +1,278 @@
+package biosconfig
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Registry attribute types, as named in a Redfish attribute registry.
+const (
+	TypeEnumeration = "Enumeration"
+	TypeString      = "String"
+	TypeInteger     = "Integer"
+	TypeBoolean     = "Boolean"
+	TypePassword    = "Password"
+
+	// defaultRegistry is the registry of the models of a vendor without their own recorded registry.
+	defaultRegistry = "default"
+)
+
+var (
+	ErrRegistry         = errors.New("error loading attribute registry")
+	ErrRegistryNotFound = errors.New("attribute registry not found")
+)
+
+// RegistryEntry describes the allowed values of an attribute.
+type RegistryEntry struct {
+	Name        string
+	DisplayName string
+	Type        string
+	ReadOnly    bool
+	// Values are the allowed values of an enumeration.
+	Values                 []string
+	LowerBound, UpperBound *int64
+	MinLength, MaxLength   *int64
+}
+
+// Registry is a recorded BIOS attribute registry, from a Redfish attribute registry or a Supermicro Update Manager dump.
+type Registry struct {
+	entries map[string]*RegistryEntry
+	// byDisplayName looks up the Redfish entries by the display name, the name of the Supermicro settings.
+	byDisplayName map[string][]*RegistryEntry
+}
+
+// Problem is an attribute of a config not valid according to the registry.
+type Problem struct {
+	Name    string
+	Message string
+}
+
+func (p *Problem) String() string {
+	return p.Name + ": " + p.Message
+}
+
+type redfishRegistry struct {
+	RegistryEntries struct {
+		Attributes []struct {
+			AttributeName string
+			DisplayName   string
+			Type          string
+			ReadOnly      bool
+			Value         []struct {
+				ValueName string
+			}
+			LowerBound *int64
+			UpperBound *int64
+			MinLength  *int64
+			MaxLength  *int64
+		}
+	}
+}
+
+// LoadRegistry loads the registry recorded for the vendor and model in dir, as <vendor>/<model>.json or .xml,
+// falling back to <vendor>/default.json or .xml. Names are lower case, the spaces of the model are replaced by dashes.
+func LoadRegistry(dir, vendor, model string) (*Registry, error) {
+	vendor = strings.ToLower(vendor)
+	model = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(model)), " ", "-")
+
+	for _, name := range []string{model, defaultRegistry} {
+		if name == "" {
+			continue
+		}
+
+		for _, ext := range []string{".json", ".xml"} {
+			path := filepath.Join(dir, vendor, name+ext)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+
+			return LoadRegistryFile(path)
+		}
+	}
+
+	return nil, errors.Wrap(ErrRegistryNotFound, fmt.Sprintf("vendor %q model %q in %s", vendor, model, dir))
+}
+
+// LoadRegistryFile loads a Redfish attribute registry JSON file, or a Supermicro Update Manager XML dump.
+func LoadRegistryFile(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(ErrRegistry, err.Error())
+	}
+
+	registry, err := ParseRegistry(data)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	return registry, nil
+}
+
+// ParseRegistry parses a Redfish attribute registry, or a Supermicro Update Manager XML dump.
+func ParseRegistry(data []byte) (*Registry, error) {
+	data = bytes.TrimSpace(data)
+	registry := &Registry{entries: map[string]*RegistryEntry{}, byDisplayName: map[string][]*RegistryEntry{}}
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		doc := &redfishRegistry{}
+		if err := unmarshalJSON(data, doc); err != nil {
+			return nil, errors.Wrap(ErrRegistry, err.Error())
+		}
+
+		for _, attr := range doc.RegistryEntries.Attributes {
+			entry := &RegistryEntry{
+				Name:        attr.AttributeName,
+				DisplayName: attr.DisplayName,
+				Type:        attr.Type,
+				ReadOnly:    attr.ReadOnly,
+				LowerBound:  attr.LowerBound,
+				UpperBound:  attr.UpperBound,
+				MinLength:   attr.MinLength,
+				MaxLength:   attr.MaxLength,
+			}
+
+			for _, value := range attr.Value {
+				entry.Values = append(entry.Values, normalizeValue(value.ValueName))
+			}
+
+			registry.add(entry)
+		}
+
+		if len(registry.entries) == 0 {
+			return nil, errors.Wrap(ErrRegistry, "no RegistryEntries.Attributes")
+		}
+
+		return registry, nil
+	}
+
+	start := bytes.IndexByte(data, '<')
+	if start < 0 {
+		return nil, errors.Wrap(ErrRegistry, ErrFormat.Error())
+	}
+
+	cfg, err := decodeSupermicroXML(data[start:])
+	if err != nil {
+		return nil, errors.Wrap(ErrRegistry, err.Error())
+	}
+
+	cfg.walk(func(name string, setting *smcSetting) {
+		if entry, ok := setting.entry(name); ok {
+			registry.add(entry)
+		}
+	})
+
+	if len(registry.entries) == 0 {
+		return nil, errors.Wrap(ErrRegistry, "no settings")
+	}
+
+	return registry, nil
+}
+
+func (r *Registry) add(entry *RegistryEntry) {
+	r.entries[entry.Name] = entry
+
+	if entry.DisplayName != "" {
+		r.byDisplayName[entry.DisplayName] = append(r.byDisplayName[entry.DisplayName], entry)
+	}
+}
+
+// lookup returns the registry entry of the attribute, a Supermicro setting is looked up by its setting name
+// when the registry is a Redfish registry, as long as the name is not ambiguous.
+func (r *Registry) lookup(name string) (*RegistryEntry, bool) {
+	if entry, ok := r.entries[name]; ok {
+		return entry, true
+	}
+
+	idx := strings.LastIndex(name, MenuSeparator)
+	if idx < 0 {
+		return nil, false
+	}
+
+	setting := name[idx+len(MenuSeparator):]
+	if entry, ok := r.entries[setting]; ok {
+		return entry, true
+	}
+
+	if entries := r.byDisplayName[setting]; len(entries) == 1 {
+		return entries[0], true
+	}
+
+	return nil, false
+}
+
+// Validate returns the attributes not valid according to the registry, sorted by name.
+func (r *Registry) Validate(attrs Attributes) []Problem {
+	problems := []Problem{}
+
+	for name, value := range attrs {
+		entry, ok := r.lookup(name)
+		if !ok {
+			problems = append(problems, Problem{Name: name, Message: "unknown attribute"})
+			continue
+		}
+
+		if msg := entry.check(value); msg != "" {
+			problems = append(problems, Problem{Name: name, Message: msg})
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Name < problems[j].Name })
+
+	return problems
+}
+
+// check returns why the value is not valid, empty when valid.
+func (e *RegistryEntry) check(value string) string {
+	if e.ReadOnly {
+		return "read-only attribute"
+	}
+
+	switch e.Type {
+	case TypeEnumeration:
+		for _, allowed := range e.Values {
+			if value == allowed {
+				return ""
+			}
+		}
+
+		return fmt.Sprintf("value %q not one of %s", value, strings.Join(e.Values, ", "))
+	case TypeInteger:
+		// SUM numeric settings can be unsigned 64 bit values
+		n, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return fmt.Sprintf("value %q not an integer", value)
+		}
+
+		if (e.LowerBound != nil && n.Cmp(big.NewInt(*e.LowerBound)) < 0) ||
+			(e.UpperBound != nil && n.Cmp(big.NewInt(*e.UpperBound)) > 0) {
+			return fmt.Sprintf("value %s out of bounds [%s, %s]", n, bound(e.LowerBound), bound(e.UpperBound))
+		}
+	case TypeString, TypePassword:
+		n := int64(len(value))
+		if (e.MinLength != nil && n < *e.MinLength) || (e.MaxLength != nil && n > *e.MaxLength) {
+			return fmt.Sprintf("length %d out of bounds [%s, %s]", n, bound(e.MinLength), bound(e.MaxLength))
+		}
+	case TypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Sprintf("value %q not a boolean", value)
+		}
+	}
+
+	return ""
+}
+
+func bound(b *int64) string {
+	if b == nil {
+		return "-"
+	}
+
+	return strconv.FormatInt(*b, 10)
+}
@@ -0,0 +1,141 @@
+package biosconfig
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Supermicro setting types.
+const (
+	smcOption   = "Option"
+	smcCheckBox = "CheckBox"
+	smcNumeric  = "Numeric"
+	smcString   = "String"
+	smcPassword = "Password"
+)
+
+// smcMenu is a menu of a Supermicro Update Manager BIOS config, menus are nested.
+type smcMenu struct {
+	Name     string       `xml:"name,attr"`
+	Settings []smcSetting `xml:"Setting"`
+	Menus    []smcMenu    `xml:"Menu"`
+}
+
+type smcSetting struct {
+	Name           string   `xml:"name,attr"`
+	Type           string   `xml:"type,attr"`
+	SelectedOption string   `xml:"selectedOption,attr"`
+	CheckedStatus  string   `xml:"checkedStatus,attr"`
+	NumericValue   string   `xml:"numericValue,attr"`
+	StringValue    string   `xml:"StringValue"`
+	Options        []string `xml:"Information>AvailableOptions>Option"`
+	MinValue       string   `xml:"Information>MinValue"`
+	MaxValue       string   `xml:"Information>MaxValue"`
+	MinSize        string   `xml:"Information>MinSize"`
+	MaxSize        string   `xml:"Information>MaxSize"`
+}
+
+type smcBiosCfg struct {
+	Menus []smcMenu `xml:"Menu"`
+}
+
+func decodeSupermicroXML(data []byte) (*smcBiosCfg, error) {
+	cfg := &smcBiosCfg{}
+	if err := newXMLDecoder(data).Decode(cfg); err != nil {
+		return nil, errors.Wrap(ErrParse, err.Error())
+	}
+
+	return cfg, nil
+}
+
+func parseSupermicroXML(data []byte) (Attributes, error) {
+	cfg, err := decodeSupermicroXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := Attributes{}
+	cfg.walk(func(name string, setting *smcSetting) {
+		if value, ok := setting.value(); ok {
+			attrs.set(name, value)
+		}
+	})
+
+	return attrs, nil
+}
+
+// walk calls fn with each setting, the setting is named by its menu path.
+func (c *smcBiosCfg) walk(fn func(name string, setting *smcSetting)) {
+	for i := range c.Menus {
+		c.Menus[i].walk(nil, fn)
+	}
+}
+
+func (m *smcMenu) walk(path []string, fn func(name string, setting *smcSetting)) {
+	path = append(path[:len(path):len(path)], strings.TrimSpace(m.Name))
+
+	for i := range m.Settings {
+		name := strings.Join(append(path[:len(path):len(path)], strings.TrimSpace(m.Settings[i].Name)), MenuSeparator)
+		fn(name, &m.Settings[i])
+	}
+
+	for i := range m.Menus {
+		m.Menus[i].walk(path, fn)
+	}
+}
+
+// value returns the value of the setting, passwords are not compared.
+func (s *smcSetting) value() (string, bool) {
+	switch s.Type {
+	case smcOption:
+		return s.SelectedOption, true
+	case smcCheckBox:
+		return s.CheckedStatus, true
+	case smcNumeric:
+		return s.NumericValue, true
+	case smcString:
+		return s.StringValue, true
+	default:
+		return "", false
+	}
+}
+
+// entry returns the registry entry of the setting, as recorded in a Supermicro Update Manager dump.
+func (s *smcSetting) entry(name string) (*RegistryEntry, bool) {
+	entry := &RegistryEntry{Name: name}
+
+	switch s.Type {
+	case smcOption:
+		entry.Type = TypeEnumeration
+		for _, option := range s.Options {
+			entry.Values = append(entry.Values, normalizeValue(option))
+		}
+	case smcCheckBox:
+		entry.Type = TypeEnumeration
+		entry.Values = []string{"Checked", "Unchecked"}
+	case smcNumeric:
+		entry.Type = TypeInteger
+		entry.LowerBound, entry.UpperBound = smcBound(s.MinValue), smcBound(s.MaxValue)
+	case smcString:
+		entry.Type = TypeString
+		entry.MinLength, entry.MaxLength = smcBound(s.MinSize), smcBound(s.MaxSize)
+	case smcPassword:
+		entry.Type = TypePassword
+	default:
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// smcBound returns the bound of a setting, nil when not set or out of range, SUM reports unbounded values as the max uint64.
+func smcBound(value string) *int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &n
+}
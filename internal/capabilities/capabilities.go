@@ -0,0 +1,218 @@
+package capabilities
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// wildcard matches any action, or any vendor.
+const wildcard = "*"
+
+var (
+	ErrCapabilityConfig = errors.New("capability matrix configuration error")
+	ErrUnsupported      = errors.New("action not supported")
+)
+
+// Rule declares the actions supported, or not, by the servers matching the vendor,
+// and the optional model, BIOS and BMC firmware version.
+//
+// The vendor, model and versions are case insensitive glob patterns, as accepted by path.Match.
+type Rule struct {
+	Vendor      string `mapstructure:"vendor"`
+	Model       string `mapstructure:"model"`
+	BIOSVersion string `mapstructure:"bios_version"`
+	BMCVersion  string `mapstructure:"bmc_version"`
+
+	// Supported and Unsupported list the actions the rule applies to, "*" applies to all actions.
+	Supported   []string `mapstructure:"supported"`
+	Unsupported []string `mapstructure:"unsupported"`
+
+	// Reason is reported when an action is failed as unsupported.
+	Reason string `mapstructure:"reason"`
+}
+
+// Target identifies the server an action is checked for,
+// the firmware versions are empty when not known.
+type Target struct {
+	Vendor      string
+	Model       string
+	BIOSVersion string
+	BMCVersion  string
+}
+
+// defaultRules are the built-in rules, configured rules are applied over these.
+var defaultRules = []Rule{
+	{
+		Vendor:      wildcard,
+		Unsupported: []string{"set_config"},
+		Reason:      "no bmclib provider sets the BIOS configuration from a file for this vendor",
+	},
+	{
+		Vendor:    "dell",
+		Supported: []string{"set_config"},
+	},
+	{
+		Vendor:    "supermicro",
+		Supported: []string{"set_config"},
+	},
+}
+
+// Matrix resolves the actions supported by a server.
+type Matrix struct {
+	rules []Rule
+}
+
+// New returns the capability matrix of the built-in rules, and the given rules.
+func New(rules []Rule) (*Matrix, error) {
+	for i := range rules {
+		if err := rules[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	m := &Matrix{rules: make([]Rule, 0, len(defaultRules)+len(rules))}
+	m.rules = append(m.rules, defaultRules...)
+	m.rules = append(m.rules, rules...)
+
+	return m, nil
+}
+
+// Check returns ErrUnsupported with the rule reason when the action is not supported by the target.
+//
+// The most specific rule matching the target and action applies, a rule specificity is the number of
+// its vendor, model and version fields set, and a configured rule wins over a built-in rule on a tie.
+// An action no rule applies to is supported.
+func (m *Matrix) Check(target *Target, action string) error {
+	var matched *Rule
+
+	specificity := -1
+	for i := range m.rules {
+		r := &m.rules[i]
+		if r.applies(action) == nil || !r.matches(target) {
+			continue
+		}
+
+		if s := r.specificity(); s >= specificity {
+			matched = r
+			specificity = s
+		}
+	}
+
+	if matched == nil || *matched.applies(action) {
+		return nil
+	}
+
+	reason := matched.Reason
+	if reason == "" {
+		reason = "not supported on " + target.String()
+	}
+
+	return errors.Wrap(ErrUnsupported, action+": "+reason)
+}
+
+// NeedsVersions returns true when a rule for the target vendor and model matches on firmware versions,
+// Check is then to be called with the firmware versions set.
+func (m *Matrix) NeedsVersions(target *Target) bool {
+	for i := range m.rules {
+		r := &m.rules[i]
+		if r.BIOSVersion == "" && r.BMCVersion == "" {
+			continue
+		}
+
+		if match(r.Vendor, target.Vendor) && match(r.Model, target.Model) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Target) String() string {
+	s := t.Vendor
+	if t.Model != "" {
+		s += " " + t.Model
+	}
+
+	if t.BIOSVersion != "" {
+		s += ", bios " + t.BIOSVersion
+	}
+
+	if t.BMCVersion != "" {
+		s += ", bmc " + t.BMCVersion
+	}
+
+	return s
+}
+
+// applies returns whether the rule declares the action supported, nil is returned when the rule
+// does not list the action, an action listed as both supported and unsupported is unsupported.
+func (r *Rule) applies(action string) *bool {
+	supported := false
+	for _, a := range r.Unsupported {
+		if a == wildcard || a == action {
+			return &supported
+		}
+	}
+
+	supported = true
+	for _, a := range r.Supported {
+		if a == wildcard || a == action {
+			return &supported
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) matches(t *Target) bool {
+	return match(r.Vendor, t.Vendor) &&
+		match(r.Model, t.Model) &&
+		match(r.BIOSVersion, t.BIOSVersion) &&
+		match(r.BMCVersion, t.BMCVersion)
+}
+
+func (r *Rule) specificity() int {
+	s := 0
+	for _, p := range []string{r.Vendor, r.Model, r.BIOSVersion, r.BMCVersion} {
+		if p != "" && p != wildcard {
+			s++
+		}
+	}
+
+	return s
+}
+
+func (r *Rule) validate() error {
+	if r.Vendor == "" {
+		return errors.Wrap(ErrCapabilityConfig, "rule requires a vendor")
+	}
+
+	if len(r.Supported) == 0 && len(r.Unsupported) == 0 {
+		return errors.Wrap(ErrCapabilityConfig, "rule for "+r.Vendor+" lists no actions")
+	}
+
+	for _, p := range []string{r.Vendor, r.Model, r.BIOSVersion, r.BMCVersion} {
+		if _, err := path.Match(strings.ToLower(p), ""); err != nil {
+			return errors.Wrap(ErrCapabilityConfig, "invalid pattern "+p+": "+err.Error())
+		}
+	}
+
+	return nil
+}
+
+// match returns true when the value matches the pattern, an empty pattern matches any value,
+// and a value which is not known only matches an empty pattern.
+func match(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	if value == "" {
+		return false
+	}
+
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return ok
+}
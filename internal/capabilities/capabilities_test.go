@@ -0,0 +1,101 @@
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	rules := []Rule{
+		{
+			Vendor:      "acme",
+			Unsupported: []string{"*"},
+			Reason:      "acme BMCs are not managed",
+		},
+		{
+			Vendor:      "supermicro",
+			Model:       "x10*",
+			Unsupported: []string{"secure_boot_enable", "set_config"},
+		},
+		{
+			Vendor:      "dell",
+			BMCVersion:  "5.*",
+			Unsupported: []string{"set_boot_mode"},
+			Reason:      "boot mode conversion fails on iDRAC 5",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		target  Target
+		action  string
+		wantErr string
+	}{
+		{"no rule", Target{Vendor: "hpe"}, "reset_config", ""},
+		{"built-in unsupported", Target{Vendor: "hpe"}, "set_config", "set_config: no bmclib provider sets the BIOS configuration from a file for this vendor"},
+		{"built-in supported", Target{Vendor: "Dell"}, "set_config", ""},
+		{"all actions unsupported", Target{Vendor: "acme"}, "pending_config", "pending_config: acme BMCs are not managed"},
+		{"model glob", Target{Vendor: "supermicro", Model: "X10DRi"}, "set_config", "set_config: not supported on supermicro X10DRi"},
+		{"model glob no match", Target{Vendor: "supermicro", Model: "X11DPH"}, "set_config", ""},
+		{"model action not listed", Target{Vendor: "supermicro", Model: "X10DRi"}, "reset_config", ""},
+		{"bmc version", Target{Vendor: "dell", BMCVersion: "5.10.00"}, "set_boot_mode", "set_boot_mode: boot mode conversion fails on iDRAC 5"},
+		{"bmc version no match", Target{Vendor: "dell", BMCVersion: "7.00.00"}, "set_boot_mode", ""},
+		{"bmc version unknown", Target{Vendor: "dell"}, "set_boot_mode", ""},
+	}
+
+	m, err := New(rules)
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.Check(&tt.target, tt.action)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrUnsupported)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfiguredRuleOverridesDefault(t *testing.T) {
+	m, err := New([]Rule{{Vendor: "asrockrack", Supported: []string{"set_config"}}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.Check(&Target{Vendor: "asrockrack"}, "set_config"))
+
+	m, err = New([]Rule{{Vendor: "dell", Unsupported: []string{"set_config"}}})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, m.Check(&Target{Vendor: "dell"}, "set_config"), ErrUnsupported)
+}
+
+func TestNeedsVersions(t *testing.T) {
+	m, err := New([]Rule{{Vendor: "dell", Model: "R6515", BIOSVersion: "2.*", Unsupported: []string{"set_boot_mode"}}})
+	assert.NoError(t, err)
+
+	assert.True(t, m.NeedsVersions(&Target{Vendor: "Dell", Model: "r6515"}))
+	assert.False(t, m.NeedsVersions(&Target{Vendor: "Dell", Model: "R640"}))
+	assert.False(t, m.NeedsVersions(&Target{Vendor: "supermicro"}))
+}
+
+func TestNewInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"no vendor", Rule{Unsupported: []string{"*"}}},
+		{"no actions", Rule{Vendor: "dell"}},
+		{"invalid pattern", Rule{Vendor: "dell", Model: "[", Unsupported: []string{"*"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New([]Rule{tt.rule})
+			assert.ErrorIs(t, err, ErrCapabilityConfig)
+		})
+	}
+}
@@ -3,13 +3,21 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jeremywohl/flatten"
 	"github.com/metal-toolbox/rivets/v2/events"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 
+	"github.com/metal-toolbox/bioscfg/internal/capabilities"
+	"github.com/metal-toolbox/bioscfg/internal/health"
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+	"github.com/metal-toolbox/bioscfg/internal/profiling"
+	"github.com/metal-toolbox/bioscfg/internal/publisher"
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
 	"github.com/metal-toolbox/bioscfg/internal/store/fleetdb"
 )
 
@@ -17,12 +25,125 @@ var (
 	ErrConfig = errors.New("configuration error")
 )
 
+const (
+	defaultBiosJobTimeout      = 30 * time.Minute
+	defaultBiosJobPollInterval = 30 * time.Second
+
+	defaultBootWaitTimeout      = 30 * time.Minute
+	defaultBootWaitPollInterval = 15 * time.Second
+	defaultBootWaitStuckTimeout = 10 * time.Minute
+
+	defaultShutdownGracePeriod = 15 * time.Minute
+
+	defaultBMCRecoveryMaxResetsPerDay = 1
+	defaultBMCRecoveryTimeout         = 10 * time.Minute
+
+	// shorter than the controller handler timeout, for the deadline to be reported.
+	defaultTaskDeadline  = 150 * time.Minute
+	defaultWriteDeadline = 5 * time.Minute
+
+	defaultOrchestratorPollInterval = 30 * time.Second
+)
+
+// Modes the controller receives conditions in.
+const (
+	// ModeNats receives the conditions from the NATS JetStream, the default.
+	ModeNats = "nats"
+
+	// ModeOrchestrator fetches the conditions of the configured servers from the condition orchestrator API,
+	// for sites without JetStream.
+	ModeOrchestrator = "orchestrator"
+)
+
 type Configuration struct {
-	FacilityCode string    `mapstructure:"facility"`
-	LogLevel     string    `mapstructure:"log_level"`
-	Endpoints    Endpoints `mapstructure:"endpoints"`
-	Dryrun       bool      `mapstructure:"dryrun"`
-	Concurrency  int       `mapstructure:"concurrency"`
+	FacilityCode string     `mapstructure:"facility"`
+	LogLevel     string     `mapstructure:"log_level"`
+	Mode         string     `mapstructure:"mode"`
+	Endpoints    Endpoints  `mapstructure:"endpoints"`
+	Dryrun       bool       `mapstructure:"dryrun"`
+	Concurrency  int        `mapstructure:"concurrency"`
+	BMC          bmc.Config `mapstructure:"bmc"`
+	BiosJobs     BiosJobs   `mapstructure:"bios_jobs"`
+	BootWait     BootWait   `mapstructure:"boot_wait"`
+
+	// ShutdownGracePeriod is the time in-flight conditions are given to complete on shutdown,
+	// it is to be shorter than the pod termination grace period.
+	ShutdownGracePeriod time.Duration `mapstructure:"shutdown_grace_period"`
+
+	// StatusPublisher configures the rate limiting and retries of condition status updates.
+	StatusPublisher publisher.Config `mapstructure:"status_publisher"`
+
+	// Capabilities are applied over the built-in capability matrix rules.
+	Capabilities []capabilities.Rule `mapstructure:"capabilities"`
+
+	// Listen defines the listen addresses of the HTTP endpoints.
+	Listen Listen `mapstructure:"listen"`
+
+	// Deadlines bounds the time a condition and its steps are given.
+	Deadlines Deadlines `mapstructure:"deadlines"`
+
+	// Facilities are the facilities conditions are received for, each with its own NATS subscription,
+	// defaults to the single facility set in facility.
+	Facilities []Facility `mapstructure:"facilities"`
+}
+
+// Facility is a facility conditions are received for.
+type Facility struct {
+	Code string `mapstructure:"code"`
+
+	// Concurrency is the number of conditions of the facility handled at once, defaults to concurrency.
+	Concurrency int `mapstructure:"concurrency"`
+
+	// FleetDB is the fleetdb of the facility, defaults to endpoints.fleetdb.
+	FleetDB *fleetdb.Config `mapstructure:"fleetdb"`
+}
+
+// Deadlines bounds the time a condition and its steps are given,
+// the condition is marked as failed, naming the step, once a deadline is exceeded.
+type Deadlines struct {
+	// Task is the deadline of a condition, from the time it is received.
+	Task time.Duration `mapstructure:"task"`
+
+	// Actions set the task deadline per action.
+	Actions map[string]time.Duration `mapstructure:"actions"`
+
+	// Steps set the deadline per step - connect, preflight, fetch_config, reboot, bios_job and verify.
+	Steps map[string]time.Duration `mapstructure:"steps"`
+
+	// Max caps the deadlines set in the task parameters, defaults to the task deadline.
+	Max time.Duration `mapstructure:"max"`
+
+	// Write bounds a change made on the BMC, which once started is not cancelled by the deadlines.
+	Write time.Duration `mapstructure:"write"`
+}
+
+// Listen defines the listen addresses of the metrics, health and profiling endpoints.
+type Listen struct {
+	// Metrics is the address of the prometheus /metrics endpoint.
+	Metrics string `mapstructure:"metrics"`
+
+	// Health is the address of the /healthz and /readyz endpoints.
+	Health string `mapstructure:"health"`
+
+	// Profiling is the address of the pprof endpoints, served when profiling is enabled.
+	Profiling string `mapstructure:"profiling"`
+}
+
+// BiosJobs configures the tracking of the BIOS configuration jobs the BMC creates on a BIOS change.
+type BiosJobs struct {
+	// Wait for the job to complete before the condition is marked as successful.
+	Wait bool `mapstructure:"wait"`
+
+	// Reboot the server when the job is pending and the action did not already reboot it,
+	// some vendors only run the job on the next boot.
+	Reboot bool `mapstructure:"reboot"`
+
+	// Timeout is the maximum time to wait for the job to complete,
+	// or for a change to be verified on the server after a reboot.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// PollInterval is the interval between job status, or change verification checks.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
 }
 
 type Endpoints struct {
@@ -31,6 +152,39 @@ type Endpoints struct {
 
 	// FleetDBConfig defines the fleetdb client configuration parameters
 	FleetDB fleetdb.Config `mapstructure:"fleetdb"`
+
+	// Orchestrator defines the condition orchestrator API client parameters, used in the orchestrator mode.
+	Orchestrator Orchestrator `mapstructure:"orchestrator"`
+}
+
+// Orchestrator configures the orchestrator mode, in which the conditions of each server are fetched
+// from the condition orchestrator API, and their status published to it.
+type Orchestrator struct {
+	URL              string   `mapstructure:"url"`
+	OidcIssuerURL    string   `mapstructure:"oidc_issuer_url"`
+	OidcAudienceURL  string   `mapstructure:"oidc_audience_url"`
+	OidcClientSecret string   `mapstructure:"oidc_client_secret"`
+	OidcClientID     string   `mapstructure:"oidc_client_id"`
+	OidcClientScopes []string `mapstructure:"oidc_client_scopes"`
+	Authenticate     bool     `mapstructure:"authenticate"`
+
+	// ServerIDs are the servers the conditions are fetched for, the API is queried per server.
+	ServerIDs []string `mapstructure:"server_ids"`
+
+	// PollInterval is the interval between the condition queries of a server, a queued condition is run until it completes.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+// BootWait configures the POST code sampling while waiting for the host to boot after a reboot.
+type BootWait struct {
+	// Timeout is the maximum time to wait for the host to boot.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// PollInterval is the interval between POST code samples.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// StuckTimeout is the time after which the host is considered stuck, when the POST code does not change.
+	StuckTimeout time.Duration `mapstructure:"stuck_timeout"`
 }
 
 func Load(cfgFilePath, loglevel string) (*Configuration, error) {
@@ -97,10 +251,6 @@ func (cfg *Configuration) validate() error {
 		return ErrConfig
 	}
 
-	if cfg.FacilityCode == "" {
-		return errors.Wrap(ErrConfig, "no facility codes")
-	}
-
 	if cfg.LogLevel == "" {
 		cfg.LogLevel = "info"
 	}
@@ -109,6 +259,202 @@ func (cfg *Configuration) validate() error {
 		cfg.Concurrency = 1
 	}
 
+	if err := cfg.validateFacilities(); err != nil {
+		return err
+	}
+
+	if err := cfg.validateMode(); err != nil {
+		return err
+	}
+
+	if cfg.BMC.Recovery.MaxResetsPerDay == 0 {
+		cfg.BMC.Recovery.MaxResetsPerDay = defaultBMCRecoveryMaxResetsPerDay
+	}
+
+	if cfg.BMC.Recovery.Timeout == 0 {
+		cfg.BMC.Recovery.Timeout = defaultBMCRecoveryTimeout
+	}
+
+	if err := cfg.BMC.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.BiosJobs.Timeout == 0 {
+		cfg.BiosJobs.Timeout = defaultBiosJobTimeout
+	}
+
+	if cfg.BiosJobs.PollInterval == 0 {
+		cfg.BiosJobs.PollInterval = defaultBiosJobPollInterval
+	}
+
+	if cfg.BiosJobs.Timeout < 0 || cfg.BiosJobs.PollInterval < 0 {
+		return errors.Wrap(ErrConfig, "bios_jobs timeout and poll_interval must be positive")
+	}
+
+	if cfg.BootWait.Timeout == 0 {
+		cfg.BootWait.Timeout = defaultBootWaitTimeout
+	}
+
+	if cfg.BootWait.PollInterval == 0 {
+		cfg.BootWait.PollInterval = defaultBootWaitPollInterval
+	}
+
+	if cfg.BootWait.StuckTimeout == 0 {
+		cfg.BootWait.StuckTimeout = defaultBootWaitStuckTimeout
+	}
+
+	if cfg.BootWait.Timeout < 0 || cfg.BootWait.PollInterval < 0 || cfg.BootWait.StuckTimeout < 0 {
+		return errors.Wrap(ErrConfig, "boot_wait timeout, poll_interval and stuck_timeout must be positive")
+	}
+
+	if cfg.ShutdownGracePeriod == 0 {
+		cfg.ShutdownGracePeriod = defaultShutdownGracePeriod
+	}
+
+	if cfg.ShutdownGracePeriod < 0 {
+		return errors.Wrap(ErrConfig, "shutdown_grace_period must be positive")
+	}
+
+	if err := cfg.Deadlines.validate(); err != nil {
+		return err
+	}
+
+	if cfg.Listen.Metrics == "" {
+		cfg.Listen.Metrics = metrics.Endpoint
+	}
+
+	if cfg.Listen.Health == "" {
+		cfg.Listen.Health = health.Endpoint
+	}
+
+	if cfg.Listen.Profiling == "" {
+		cfg.Listen.Profiling = profiling.Endpoint
+	}
+
+	return nil
+}
+
+func (cfg *Configuration) validateFacilities() error {
+	if len(cfg.Facilities) == 0 {
+		if cfg.FacilityCode == "" {
+			return errors.Wrap(ErrConfig, "no facility codes")
+		}
+
+		cfg.Facilities = []Facility{{Code: cfg.FacilityCode}}
+	}
+
+	seen := make(map[string]bool, len(cfg.Facilities))
+	for i := range cfg.Facilities {
+		f := &cfg.Facilities[i]
+		if f.Code == "" {
+			return errors.Wrap(ErrConfig, "facilities code is required")
+		}
+
+		if seen[f.Code] {
+			return errors.Wrap(ErrConfig, "duplicate facility: "+f.Code)
+		}
+
+		seen[f.Code] = true
+
+		if f.Concurrency == 0 {
+			f.Concurrency = cfg.Concurrency
+		}
+
+		if f.Concurrency < 0 {
+			return errors.Wrap(ErrConfig, "facilities "+f.Code+" concurrency must be positive")
+		}
+	}
+
+	// conditions run from the command line are in the first facility
+	if cfg.FacilityCode == "" {
+		cfg.FacilityCode = cfg.Facilities[0].Code
+	}
+
+	return nil
+}
+
+func (cfg *Configuration) validateMode() error {
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = ModeNats
+	case ModeNats:
+	case ModeOrchestrator:
+		// the BMC resets are counted in a NATS KV bucket
+		if cfg.BMC.Recovery.Enabled {
+			return errors.Wrap(ErrConfig, "bmc.recovery requires the nats mode")
+		}
+
+		if len(cfg.Facilities) > 1 {
+			return errors.Wrap(ErrConfig, "the orchestrator mode serves a single facility")
+		}
+
+		return cfg.Endpoints.Orchestrator.validate()
+	default:
+		return errors.Wrap(ErrConfig, "unknown mode: "+cfg.Mode)
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) validate() error {
+	if o.URL == "" {
+		return errors.Wrap(ErrConfig, "endpoints.orchestrator.url is required in the orchestrator mode")
+	}
+
+	if len(o.ServerIDs) == 0 {
+		return errors.Wrap(ErrConfig, "endpoints.orchestrator.server_ids is required in the orchestrator mode")
+	}
+
+	for _, id := range o.ServerIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return errors.Wrap(ErrConfig, "endpoints.orchestrator.server_ids: "+err.Error())
+		}
+	}
+
+	if o.Authenticate && (o.OidcIssuerURL == "" || o.OidcClientID == "" || o.OidcClientSecret == "") {
+		return errors.Wrap(ErrConfig, "endpoints.orchestrator oidc issuer url, client id and secret are required to authenticate")
+	}
+
+	if o.PollInterval == 0 {
+		o.PollInterval = defaultOrchestratorPollInterval
+	}
+
+	if o.PollInterval < 0 {
+		return errors.Wrap(ErrConfig, "endpoints.orchestrator.poll_interval must be positive")
+	}
+
+	return nil
+}
+
+func (d *Deadlines) validate() error {
+	if d.Task == 0 {
+		d.Task = defaultTaskDeadline
+	}
+
+	if d.Max == 0 {
+		d.Max = d.Task
+	}
+
+	if d.Write == 0 {
+		d.Write = defaultWriteDeadline
+	}
+
+	if d.Task < 0 || d.Max < 0 || d.Write < 0 {
+		return errors.Wrap(ErrConfig, "deadlines task, max and write must be positive")
+	}
+
+	for name, deadline := range d.Actions {
+		if deadline <= 0 {
+			return errors.Wrap(ErrConfig, "deadlines.actions."+name+" must be positive")
+		}
+	}
+
+	for name, deadline := range d.Steps {
+		if deadline <= 0 {
+			return errors.Wrap(ErrConfig, "deadlines.steps."+name+" must be positive")
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metal-toolbox/bioscfg/internal/store/bmc"
+	"github.com/metal-toolbox/bioscfg/internal/store/fleetdb"
+)
+
+func TestValidateFacilities(t *testing.T) {
+	fleetdbCfg := &fleetdb.Config{URL: "http://fleetdb-ams:8000"}
+
+	tests := []struct {
+		name         string
+		cfg          *Configuration
+		want         []Facility
+		wantFacility string
+		wantErr      bool
+	}{
+		{
+			"single facility",
+			&Configuration{FacilityCode: "sandbox", Concurrency: 5},
+			[]Facility{{Code: "sandbox", Concurrency: 5}},
+			"sandbox",
+			false,
+		},
+		{
+			"facilities",
+			&Configuration{Concurrency: 2, Facilities: []Facility{{Code: "da1", Concurrency: 10}, {Code: "ams1", FleetDB: fleetdbCfg}}},
+			[]Facility{{Code: "da1", Concurrency: 10}, {Code: "ams1", Concurrency: 2, FleetDB: fleetdbCfg}},
+			"da1",
+			false,
+		},
+		{"no facility", &Configuration{Concurrency: 1}, nil, "", true},
+		{"no facility code", &Configuration{Concurrency: 1, Facilities: []Facility{{Concurrency: 1}}}, nil, "", true},
+		{"duplicate facility", &Configuration{Concurrency: 1, Facilities: []Facility{{Code: "da1"}, {Code: "da1"}}}, nil, "", true},
+		{"negative concurrency", &Configuration{Concurrency: 1, Facilities: []Facility{{Code: "da1", Concurrency: -1}}}, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateFacilities()
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrConfig), err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.cfg.Facilities)
+			assert.Equal(t, tt.wantFacility, tt.cfg.FacilityCode)
+		})
+	}
+}
+
+func TestValidateBMC(t *testing.T) {
+	cfg := &Configuration{FacilityCode: "sandbox"}
+	assert.NoError(t, cfg.validate())
+	assert.Equal(t, defaultBMCRecoveryMaxResetsPerDay, cfg.BMC.Recovery.MaxResetsPerDay)
+	assert.Equal(t, defaultBMCRecoveryTimeout, cfg.BMC.Recovery.Timeout)
+
+	cfg = &Configuration{FacilityCode: "sandbox"}
+	cfg.BMC.TLS.Mode = "strict"
+	assert.True(t, errors.Is(cfg.validate(), bmc.ErrBMCConfig))
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	Endpoint = "0.0.0.0:9092"
+
+	// checkTimeout bounds the time all the checks of a probe are given.
+	checkTimeout = 5 * time.Second
+
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Check reports the state of a dependency, the returned detail is included in the report,
+// an error fails the probe.
+type Check func(ctx context.Context) (string, error)
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report is the response body of the health endpoints.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Server serves the /healthz liveness and /readyz readiness endpoints,
+// the readiness endpoint runs both the liveness and readiness checks.
+type Server struct {
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+// New returns a health server without any checks.
+func New() *Server {
+	return &Server{}
+}
+
+// Liveness adds a check failing which the process is to be restarted.
+func (s *Server) Liveness(name string, check Check) {
+	s.liveness = append(s.liveness, namedCheck{name: name, check: check})
+}
+
+// Readiness adds a check failing which the process is not to be sent work.
+func (s *Server) Readiness(name string, check Check) {
+	s.readiness = append(s.readiness, namedCheck{name: name, check: check})
+}
+
+// Handler returns the handler serving the health endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, s.liveness)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		checks := make([]namedCheck, 0, len(s.liveness)+len(s.readiness))
+		checks = append(checks, s.liveness...)
+		checks = append(checks, s.readiness...)
+
+		serve(w, r, checks)
+	})
+
+	return mux
+}
+
+// ListenAndServe exposes the health endpoints on the given address.
+func (s *Server) ListenAndServe(addr string) {
+	go func() {
+		server := &http.Server{
+			Addr:              addr,
+			Handler:           s.Handler(),
+			ReadHeaderTimeout: 2 * time.Second, // nolint:gomnd // time duration value is clear as is.
+		}
+
+		if err := server.ListenAndServe(); err != nil {
+			slog.Error("Failed to start health server", "error", err)
+			os.Exit(1)
+		}
+	}()
+}
+
+func serve(w http.ResponseWriter, r *http.Request, checks []namedCheck) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	report := run(ctx, checks)
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != StatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("Failed to write health report", "error", err)
+	}
+}
+
+func run(ctx context.Context, checks []namedCheck) *Report {
+	report := &Report{Status: StatusOK, Checks: make([]CheckResult, 0, len(checks))}
+
+	for _, c := range checks {
+		result := CheckResult{Name: c.name, Status: StatusOK}
+
+		detail, err := c.check(ctx)
+		if err != nil {
+			result.Status = StatusFailing
+			detail = err.Error()
+			report.Status = StatusFailing
+		}
+
+		result.Detail = detail
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	ok := func(context.Context) (string, error) { return "connected", nil }
+	failing := func(context.Context) (string, error) { return "", errors.New("saturated") }
+
+	tests := []struct {
+		name       string
+		liveness   Check
+		readiness  Check
+		path       string
+		wantCode   int
+		wantStatus string
+		wantChecks []CheckResult
+	}{
+		{
+			name:       "live",
+			liveness:   ok,
+			readiness:  failing,
+			path:       "/healthz",
+			wantCode:   http.StatusOK,
+			wantStatus: StatusOK,
+			wantChecks: []CheckResult{{Name: "nats", Status: StatusOK, Detail: "connected"}},
+		},
+		{
+			name:       "ready",
+			liveness:   ok,
+			readiness:  ok,
+			path:       "/readyz",
+			wantCode:   http.StatusOK,
+			wantStatus: StatusOK,
+			wantChecks: []CheckResult{
+				{Name: "nats", Status: StatusOK, Detail: "connected"},
+				{Name: "handlers", Status: StatusOK, Detail: "connected"},
+			},
+		},
+		{
+			name:       "not ready",
+			liveness:   ok,
+			readiness:  failing,
+			path:       "/readyz",
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusFailing,
+			wantChecks: []CheckResult{
+				{Name: "nats", Status: StatusOK, Detail: "connected"},
+				{Name: "handlers", Status: StatusFailing, Detail: "saturated"},
+			},
+		},
+		{
+			name:       "not live",
+			liveness:   failing,
+			readiness:  ok,
+			path:       "/readyz",
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusFailing,
+			wantChecks: []CheckResult{
+				{Name: "nats", Status: StatusFailing, Detail: "saturated"},
+				{Name: "handlers", Status: StatusOK, Detail: "connected"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New()
+			s.Liveness("nats", tc.liveness)
+			s.Readiness("handlers", tc.readiness)
+
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, http.NoBody))
+
+			assert.Equal(t, tc.wantCode, rec.Code)
+
+			report := &Report{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), report))
+			assert.Equal(t, tc.wantStatus, report.Status)
+			assert.Equal(t, tc.wantChecks, report.Checks)
+		})
+	}
+}
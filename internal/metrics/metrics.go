@@ -25,6 +25,13 @@ var (
 	StoreQueryErrorCount    *prometheus.CounterVec
 
 	NATSErrors *prometheus.CounterVec
+
+	BMCUnverifiedConnections *prometheus.CounterVec
+	BMCErrors                *prometheus.CounterVec
+	BMCRecoveries            *prometheus.CounterVec
+	BMCCredentialsInvalid    *prometheus.CounterVec
+	UnsupportedActions       *prometheus.CounterVec
+	ConditionsCancelled      *prometheus.CounterVec
 )
 
 func init() {
@@ -41,7 +48,7 @@ func init() {
 			Name: "bioscfg_condition_duration_seconds",
 			Help: "A summary metric to measure the total time spent in completing each condition",
 		},
-		[]string{"condition", "state"},
+		[]string{"condition", "state", "facility"},
 	)
 
 	StoreQueryErrorCount = promauto.NewCounterVec(
@@ -59,15 +66,63 @@ func init() {
 		},
 		[]string{"operation"},
 	)
+
+	BMCUnverifiedConnections = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bioscfg_bmc_unverified_tls_connections",
+			Help: "A count of TLS connections made to BMCs without verifying the BMC certificate.",
+		},
+		[]string{"vendor", "facility"},
+	)
+
+	BMCErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bioscfg_bmc_errors",
+			Help: "A count of errors returned by BMC operations, by error class.",
+		},
+		[]string{"operation", "class", "facility"},
+	)
+
+	BMCRecoveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bioscfg_bmc_recoveries",
+			Help: "A count of BMC resets to recover unresponsive BMCs, by result.",
+		},
+		[]string{"vendor", "result", "facility"},
+	)
+
+	BMCCredentialsInvalid = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bioscfg_bmc_credentials_invalid",
+			Help: "A count of BMC logins failed with the fleetdb credential, after refreshing it.",
+		},
+		[]string{"vendor", "facility"},
+	)
+
+	UnsupportedActions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bioscfg_unsupported_actions",
+			Help: "A count of actions failed by the capability matrix, as not supported by the server.",
+		},
+		[]string{"vendor", "model", "action", "facility"},
+	)
+
+	ConditionsCancelled = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bioscfg_conditions_cancelled",
+			Help: "A count of conditions cancelled on an operator request while running.",
+		},
+		[]string{"action", "facility"},
+	)
 }
 
-// ListenAndServe exposes prometheus metrics as /metrics
-func ListenAndServe() {
+// ListenAndServe exposes prometheus metrics as /metrics on the given address
+func ListenAndServe(addr string) {
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
 
 		server := &http.Server{
-			Addr:              Endpoint,
+			Addr:              addr,
 			ReadHeaderTimeout: 2 * time.Second, // nolint:gomnd // time duration value is clear as is.
 		}
 
@@ -93,3 +148,27 @@ func RegisterSpanEvent(span trace.Span, condition *rctypes.Condition, controller
 func NATSError(op string) {
 	NATSErrors.WithLabelValues(op).Inc()
 }
+
+func BMCUnverifiedConnection(vendor, facility string) {
+	BMCUnverifiedConnections.WithLabelValues(vendor, facility).Inc()
+}
+
+func BMCError(op, class, facility string) {
+	BMCErrors.WithLabelValues(op, class, facility).Inc()
+}
+
+func BMCRecovery(vendor, result, facility string) {
+	BMCRecoveries.WithLabelValues(vendor, result, facility).Inc()
+}
+
+func BMCCredentialInvalid(vendor, facility string) {
+	BMCCredentialsInvalid.WithLabelValues(vendor, facility).Inc()
+}
+
+func UnsupportedAction(vendor, model, action, facility string) {
+	UnsupportedActions.WithLabelValues(vendor, model, action, facility).Inc()
+}
+
+func ConditionCancelled(action, facility string) {
+	ConditionsCancelled.WithLabelValues(action, facility).Inc()
+}
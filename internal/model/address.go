@@ -0,0 +1,132 @@
+package model
+
+import (
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BMCAddress is the network address of a BMC,
+// the Host is an IPv4 address, IPv6 address with an optional zone, or a hostname.
+type BMCAddress struct {
+	Host string
+	// Port is empty when the BMC listens on the default port.
+	Port string
+}
+
+// ParseBMCAddress parses a BMC address in the forms - host, host:port, ipv6, [ipv6] or [ipv6]:port
+func ParseBMCAddress(s string) (BMCAddress, error) {
+	addr := BMCAddress{}
+	value := strings.TrimSpace(s)
+
+	invalid := func(reason string) error {
+		return errors.Wrap(ErrInvalidBMCAddress, strconv.Quote(s)+": "+reason)
+	}
+
+	switch {
+	case value == "":
+		return addr, invalid("empty address")
+	case strings.Contains(value, "/"):
+		return addr, invalid("expected a host with an optional port, not a URL")
+	case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+		// bracketed ipv6 without a port
+		addr.Host = value[1 : len(value)-1]
+	case strings.HasPrefix(value, "["), strings.Count(value, ":") == 1:
+		host, port, err := net.SplitHostPort(value)
+		if err != nil {
+			return addr, invalid(err.Error())
+		}
+
+		addr.Host, addr.Port = host, port
+	default:
+		// hostname, ipv4 or an ipv6 without brackets
+		addr.Host = value
+	}
+
+	if err := validateBMCHost(addr.Host); err != nil {
+		return BMCAddress{}, invalid(err.Error())
+	}
+
+	if addr.Port != "" {
+		port, err := strconv.Atoi(addr.Port)
+		if err != nil || port < 1 || port > 65535 {
+			return BMCAddress{}, invalid("invalid port " + addr.Port)
+		}
+	}
+
+	return addr, nil
+}
+
+func validateBMCHost(host string) error {
+	if host == "" {
+		return errors.New("empty host")
+	}
+
+	if strings.Contains(host, ":") {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return errors.New("invalid IPv6 address")
+		}
+
+		return nil
+	}
+
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+
+	// RFC 1123 hostname
+	if len(host) > 253 {
+		return errors.New("hostname too long")
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return errors.New("invalid hostname label " + strconv.Quote(label))
+		}
+
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+				return errors.New("invalid hostname label " + strconv.Quote(label))
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsZero returns true when no address is set.
+func (a BMCAddress) IsZero() bool {
+	return a.Host == ""
+}
+
+// String returns the address as given in the inventory form.
+func (a BMCAddress) String() string {
+	if a.Port == "" {
+		return a.Host
+	}
+
+	return net.JoinHostPort(a.Host, a.Port)
+}
+
+// HostPort returns the host:port to dial, the default port is used when none was set.
+func (a BMCAddress) HostPort(defaultPort string) string {
+	port := a.Port
+	if port == "" {
+		port = defaultPort
+	}
+
+	return net.JoinHostPort(a.Host, port)
+}
+
+// URLHost returns the host for use in a URL, IPv6 addresses are bracketed with the zone escaped,
+// the port is not included.
+func (a BMCAddress) URLHost() string {
+	if !strings.Contains(a.Host, ":") {
+		return a.Host
+	}
+
+	return "[" + strings.Replace(a.Host, "%", "%25", 1) + "]"
+}
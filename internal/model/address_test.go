@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBMCAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    BMCAddress
+		wantErr bool
+	}{
+		{"ipv4", "10.0.0.10", BMCAddress{Host: "10.0.0.10"}, false},
+		{"ipv4 with port", "10.0.0.10:8443", BMCAddress{Host: "10.0.0.10", Port: "8443"}, false},
+		{"hostname", "bmc-r6515.example.com", BMCAddress{Host: "bmc-r6515.example.com"}, false},
+		{"hostname with port", "bmc:443", BMCAddress{Host: "bmc", Port: "443"}, false},
+		{"surrounding spaces", " 10.0.0.10 ", BMCAddress{Host: "10.0.0.10"}, false},
+		{"ipv6", "fd00::10", BMCAddress{Host: "fd00::10"}, false},
+		{"bracketed ipv6", "[fd00::10]", BMCAddress{Host: "fd00::10"}, false},
+		{"bracketed ipv6 with port", "[fd00::10]:8443", BMCAddress{Host: "fd00::10", Port: "8443"}, false},
+		{"ipv6 with zone", "fe80::1%eth0", BMCAddress{Host: "fe80::1%eth0"}, false},
+		{"empty port", "bmc:", BMCAddress{Host: "bmc"}, false},
+		{"empty", "", BMCAddress{}, true},
+		{"scheme prefix", "https://10.0.0.10", BMCAddress{}, true},
+		{"url path", "10.0.0.10/redfish/v1", BMCAddress{}, true},
+		{"port out of range", "10.0.0.10:65536", BMCAddress{}, true},
+		{"port not a number", "bmc:https", BMCAddress{}, true},
+		{"invalid ipv6", "[fd00::zz]:443", BMCAddress{}, true},
+		{"invalid hostname", "bmc_r6515", BMCAddress{}, true},
+		{"hostname label starting with a dash", "-bmc.example.com", BMCAddress{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBMCAddress(tt.input)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidBMCAddress), err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBMCAddressHosts(t *testing.T) {
+	tests := []struct {
+		name         string
+		addr         BMCAddress
+		wantString   string
+		wantHostPort string
+		wantURLHost  string
+	}{
+		{"ipv4", BMCAddress{Host: "10.0.0.10"}, "10.0.0.10", "10.0.0.10:443", "10.0.0.10"},
+		{"ipv4 with port", BMCAddress{Host: "10.0.0.10", Port: "8443"}, "10.0.0.10:8443", "10.0.0.10:8443", "10.0.0.10"},
+		{"hostname", BMCAddress{Host: "bmc"}, "bmc", "bmc:443", "bmc"},
+		{"ipv6", BMCAddress{Host: "fd00::10"}, "fd00::10", "[fd00::10]:443", "[fd00::10]"},
+		{"ipv6 with port", BMCAddress{Host: "fd00::10", Port: "8443"}, "[fd00::10]:8443", "[fd00::10]:8443", "[fd00::10]"},
+		{"ipv6 with zone", BMCAddress{Host: "fe80::1%eth0"}, "fe80::1%eth0", "[fe80::1%eth0]:443", "[fe80::1%25eth0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantString, tt.addr.String())
+			assert.Equal(t, tt.wantHostPort, tt.addr.HostPort("443"))
+			assert.Equal(t, tt.wantURLHost, tt.addr.URLHost())
+		})
+	}
+}
@@ -1,6 +0,0 @@
-package model
-
-const (
-	PowerStateOn    = "On"
-	PowerStateReset = "Reset"
-)
@@ -5,6 +5,7 @@ import (
 )
 
 var (
-	ErrConfig        = errors.New("configuration error")
-	ErrInvalidAction = errors.New("invalid action")
+	ErrConfig            = errors.New("configuration error")
+	ErrInvalidAction     = errors.New("invalid action")
+	ErrInvalidBMCAddress = errors.New("invalid BMC address")
 )
@@ -1,8 +1,6 @@
 package model
 
 import (
-	"net"
-
 	"github.com/google/uuid"
 )
 
@@ -19,10 +17,13 @@ type Asset struct {
 	ID uuid.UUID
 
 	// Device BMC attributes
-	BmcAddress  net.IP
+	BmcAddress  BMCAddress
 	BmcUsername string
 	BmcPassword string
 
+	// SHA-256 fingerprint of the BMC certificate, when pinned.
+	BmcCertFingerprint string
+
 	// Manufacturer attributes
 	Vendor string
 	Model  string
@@ -31,3 +32,16 @@ type Asset struct {
 	// Facility this Asset is hosted in.
 	FacilityCode string
 }
+
+// BiosPassword is the BIOS setup password to set on a server.
+type BiosPassword struct {
+	// New is the password to set.
+	New string
+	// Current is the password set on the server, empty when no password is set.
+	Current string
+}
+
+// String redacts the passwords, to prevent them being logged.
+func (p BiosPassword) String() string {
+	return "BiosPassword{REDACTED}"
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"strings"
+)
+
+// PowerState is the normalized power state of a server, as reported by the BMC.
+type PowerState string
+
+const (
+	PowerStateOn          PowerState = "on"
+	PowerStateOff         PowerState = "off"
+	PowerStatePoweringOn  PowerState = "powering_on"
+	PowerStatePoweringOff PowerState = "powering_off"
+	PowerStatePaused      PowerState = "paused"
+	PowerStateUnknown     PowerState = "unknown"
+)
+
+// PowerAction is a power change requested from the BMC,
+// the values are the power states accepted by the bmclib providers.
+type PowerAction string
+
+const (
+	// PowerActionOn powers on the server.
+	PowerActionOn PowerAction = "on"
+	// PowerActionOff powers off the server, without waiting for the OS to shutdown.
+	PowerActionOff PowerAction = "off"
+	// PowerActionSoftOff shuts down the OS gracefully.
+	PowerActionSoftOff PowerAction = "soft"
+	// PowerActionReset resets the server.
+	PowerActionReset PowerAction = "reset"
+	// PowerActionCycle powers the server off and on.
+	PowerActionCycle PowerAction = "cycle"
+)
+
+// ParsePowerState normalizes the power state strings returned by the bmclib providers,
+// which range from the Redfish "On" and "PoweringOff" to the ipmitool "Chassis Power is on",
+// PowerStateUnknown is returned for unknown values.
+func ParsePowerState(s string) PowerState {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "chassis power is ")
+	s = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s)
+
+	switch s {
+	case "on":
+		return PowerStateOn
+	case "off":
+		return PowerStateOff
+	case "poweringon":
+		return PowerStatePoweringOn
+	case "poweringoff":
+		return PowerStatePoweringOff
+	case "paused":
+		return PowerStatePaused
+	default:
+		return PowerStateUnknown
+	}
+}
+
+// ParsePowerAction returns the PowerAction for the given value, false is returned for unknown values.
+func ParsePowerAction(s string) (PowerAction, bool) {
+	action := PowerAction(strings.ToLower(strings.TrimSpace(s)))
+
+	switch action {
+	case PowerActionOn, PowerActionOff, PowerActionSoftOff, PowerActionReset, PowerActionCycle:
+		return action, true
+	default:
+		return "", false
+	}
+}
+
+// Restarts returns true when the action restarts the server.
+func (a PowerAction) Restarts() bool {
+	return a == PowerActionReset || a == PowerActionCycle
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePowerState(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  PowerState
+	}{
+		{"redfish on", "On", PowerStateOn},
+		{"redfish off", "Off", PowerStateOff},
+		{"redfish powering on", "PoweringOn", PowerStatePoweringOn},
+		{"redfish powering off", "PoweringOff", PowerStatePoweringOff},
+		{"redfish paused", "Paused", PowerStatePaused},
+		{"ipmitool on", "Chassis Power is on\n", PowerStateOn},
+		{"ipmitool off", "Chassis Power is off", PowerStateOff},
+		{"intelamt on", "on", PowerStateOn},
+		{"upper case", "ON", PowerStateOn},
+		{"snake case", "powering_off", PowerStatePoweringOff},
+		{"empty", "", PowerStateUnknown},
+		{"unknown", "Reset", PowerStateUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParsePowerState(tt.input))
+		})
+	}
+}
+
+func TestParsePowerAction(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   PowerAction
+		wantOK bool
+	}{
+		{"on", PowerActionOn, true},
+		{"Off", PowerActionOff, true},
+		{"soft", PowerActionSoftOff, true},
+		{"Reset", PowerActionReset, true},
+		{" CYCLE ", PowerActionCycle, true},
+		{"GracefulRestart", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParsePowerAction(tt.input)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
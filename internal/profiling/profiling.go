@@ -11,11 +11,11 @@ const (
 	ReadHeaderTimeout = 2 * time.Second
 )
 
-// Enable the profiling endpoint
-func Enable() {
+// Enable the profiling endpoint on the given address
+func Enable(addr string) {
 	go func() {
 		server := &http.Server{
-			Addr:              Endpoint,
+			Addr:              addr,
 			ReadHeaderTimeout: ReadHeaderTimeout,
 		}
 
@@ -24,5 +24,5 @@ func Enable() {
 		}
 	}()
 
-	slog.Info("profiling enabled", "endpoint", Endpoint+"/debug/pprof")
+	slog.Info("profiling enabled", "endpoint", addr+"/debug/pprof")
 }
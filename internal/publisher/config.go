@@ -0,0 +1,60 @@
+package publisher
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// default status publisher parameters, see Config
+const (
+	minInterval      = 2 * time.Second
+	retryAttempts    = 5
+	retryInterval    = time.Second
+	maxRetryInterval = 30 * time.Second
+)
+
+var (
+	ErrPublisherConfig = errors.New("status publisher configuration error")
+)
+
+// Config defines the condition status publishing parameters, unset fields are set to the defaults.
+type Config struct {
+	// MinInterval is the minimum interval between status updates of a condition,
+	// updates within the interval are coalesced, and the latest is published once the interval elapsed.
+	// Final states are always published immediately.
+	MinInterval time.Duration `mapstructure:"min_interval"`
+
+	// RetryAttempts is the number of times a failed status update is attempted.
+	RetryAttempts int `mapstructure:"retry_attempts"`
+
+	// RetryInterval is the initial interval between attempts, doubled after each attempt.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+
+	// MaxRetryInterval caps the interval between attempts.
+	MaxRetryInterval time.Duration `mapstructure:"max_retry_interval"`
+}
+
+func (cfg *Config) validate() error {
+	if cfg.MinInterval < 0 || cfg.RetryInterval < 0 || cfg.MaxRetryInterval < 0 || cfg.RetryAttempts < 0 {
+		return errors.Wrap(ErrPublisherConfig, "negative interval or retry attempts")
+	}
+
+	if cfg.MinInterval == 0 {
+		cfg.MinInterval = minInterval
+	}
+
+	if cfg.RetryAttempts == 0 {
+		cfg.RetryAttempts = retryAttempts
+	}
+
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = retryInterval
+	}
+
+	if cfg.MaxRetryInterval == 0 {
+		cfg.MaxRetryInterval = maxRetryInterval
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+)
+
+// Console prints the condition status updates in place of the NATS status publisher,
+// for conditions run from the command line.
+type Console struct {
+	mu    sync.Mutex
+	out   io.Writer
+	state rctypes.State
+	last  string
+	// printed is the timestamp of the last printed message,
+	// the status record is a window of the latest messages, it is not indexed.
+	printed time.Time
+}
+
+// NewConsole returns a Console printing to out.
+func NewConsole(out io.Writer) *Console {
+	return &Console{out: out, state: rctypes.Pending}
+}
+
+// Publish prints the status messages newer than the last printed, timestamp only updates are ignored.
+func (c *Console) Publish(_ context.Context, task *rctypes.Task[any, any], tsUpdateOnly bool) error {
+	if tsUpdateOnly {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msgs := task.Status.StatusMsgs
+	for _, msg := range msgs {
+		if !msg.Timestamp.After(c.printed) {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(c.out, "%s %-9s %s\n", msg.Timestamp.Format(time.TimeOnly), task.State, msg.Msg); err != nil {
+			return err
+		}
+
+		c.printed = msg.Timestamp
+	}
+
+	c.state = task.State
+
+	if len(msgs) > 0 {
+		c.last = msgs[len(msgs)-1].Msg
+	}
+
+	return nil
+}
+
+// State returns the last published condition state.
+func (c *Console) State() rctypes.State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
+
+// LastStatus returns the last published status message.
+func (c *Console) LastStatus() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.last
+}
@@ -0,0 +1,37 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsolePublish(t *testing.T) {
+	out := &bytes.Buffer{}
+	console := NewConsole(out)
+	task := &rctypes.Task[any, any]{State: rctypes.Active}
+
+	// more messages than the status record window
+	for i := 1; i <= 8; i++ {
+		task.Status.Append(fmt.Sprintf("step %d", i))
+		require.NoError(t, console.Publish(context.Background(), task, false))
+	}
+
+	task.State = rctypes.Failed
+	task.Status.Append("step failed")
+	require.NoError(t, console.Publish(context.Background(), task, false))
+	require.NoError(t, console.Publish(context.Background(), task, true))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 9)
+	assert.Contains(t, lines[7], "active    step 8")
+	assert.Contains(t, lines[8], "failed    step failed")
+	assert.Equal(t, rctypes.Failed, console.State())
+	assert.Equal(t, "step failed", console.LastStatus())
+}
@@ -0,0 +1,193 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/ctrl"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+)
+
+var (
+	ErrPublish = errors.New("error publishing condition status")
+)
+
+// StatusPublisher publishes the status updates of a single condition,
+// rapid updates are coalesced and rate limited, final states are published immediately,
+// and failed updates are retried with an exponential backoff.
+type StatusPublisher struct {
+	publisher ctrl.Publisher
+	cfg       Config
+	clock     Clock
+	logger    *logrus.Entry
+
+	mu sync.Mutex
+	// last is the time the last update was sent.
+	last time.Time
+	// pending is the latest coalesced update, sent when the timer fires.
+	pending *update
+	timer   Timer
+	// seq numbers the updates, in the order they were published.
+	seq uint64
+
+	// sendMu serializes the updates sent, sent is the seq of the latest update sent.
+	sendMu sync.Mutex
+	sent   uint64
+}
+
+type update struct {
+	ctx  context.Context
+	task *rctypes.Task[any, any]
+	seq  uint64
+}
+
+// New returns a StatusPublisher publishing the condition status with the given publisher.
+func New(publisher ctrl.Publisher, cfg *Config, logger *logrus.Entry) (*StatusPublisher, error) {
+	return NewWithClock(publisher, cfg, realClock{}, logger)
+}
+
+// NewWithClock returns a StatusPublisher using the given clock.
+func NewWithClock(publisher ctrl.Publisher, cfg *Config, clock Clock, logger *logrus.Entry) (*StatusPublisher, error) {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	return &StatusPublisher{
+		publisher: publisher,
+		cfg:       c,
+		clock:     clock,
+		logger:    logger,
+	}, nil
+}
+
+// Publish publishes the task status, an update within the minimum interval of the previous one is
+// coalesced with any later update, and nil is returned without waiting for it to be sent.
+//
+// Updates of tasks in a final state are sent immediately, and replace any coalesced update.
+func (p *StatusPublisher) Publish(ctx context.Context, task *rctypes.Task[any, any]) error {
+	p.mu.Lock()
+
+	p.seq++
+	u := &update{ctx: ctx, task: task, seq: p.seq}
+
+	if rctypes.StateIsComplete(task.State) {
+		p.stopTimer()
+		p.last = p.clock.Now()
+		p.mu.Unlock()
+
+		return p.send(u)
+	}
+
+	wait := p.cfg.MinInterval - p.clock.Now().Sub(p.last)
+	if wait <= 0 && p.timer == nil {
+		p.last = p.clock.Now()
+		p.mu.Unlock()
+
+		return p.send(u)
+	}
+
+	p.pending = u
+	if p.timer == nil {
+		p.timer = p.clock.AfterFunc(wait, p.flushPending)
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Flush sends the coalesced update, if any, without waiting for the minimum interval.
+func (p *StatusPublisher) Flush() error {
+	p.mu.Lock()
+	u := p.pending
+	p.stopTimer()
+
+	if u == nil {
+		p.mu.Unlock()
+		return nil
+	}
+
+	p.last = p.clock.Now()
+	p.mu.Unlock()
+
+	return p.send(u)
+}
+
+func (p *StatusPublisher) flushPending() {
+	p.mu.Lock()
+	u := p.pending
+	p.pending = nil
+	p.timer = nil
+
+	if u == nil {
+		p.mu.Unlock()
+		return
+	}
+
+	p.last = p.clock.Now()
+	p.mu.Unlock()
+
+	if err := p.send(u); err != nil {
+		p.logger.WithError(err).Warn("coalesced condition status update dropped")
+	}
+}
+
+// stopTimer discards the coalesced update, p.mu is expected to be held.
+func (p *StatusPublisher) stopTimer() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+
+	p.pending = nil
+}
+
+// send publishes the update, retrying with an exponential backoff,
+// updates older than the last update sent are skipped, so a final state is never overwritten.
+func (p *StatusPublisher) send(u *update) error {
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+
+	if u.seq <= p.sent {
+		return nil
+	}
+
+	interval := p.cfg.RetryInterval
+
+	for attempt := 1; ; attempt++ {
+		err := p.publisher.Publish(u.ctx, u.task, false)
+		if err == nil {
+			p.sent = u.seq
+			return nil
+		}
+
+		metrics.NATSError("publish-condition-status")
+
+		if attempt >= p.cfg.RetryAttempts {
+			return errors.Wrap(ErrPublish, err.Error())
+		}
+
+		p.logger.WithFields(logrus.Fields{
+			"attempt": attempt,
+			"state":   u.task.State,
+			"err":     err.Error(),
+		}).Warn("condition status publish failed, retrying")
+
+		select {
+		case <-p.clock.After(interval):
+		case <-u.ctx.Done():
+			return errors.Wrap(ErrPublish, u.ctx.Err().Error())
+		}
+
+		interval = min(interval*2, p.cfg.MaxRetryInterval)
+	}
+}
@@ -0,0 +1,258 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock fires timers when the time is advanced past their deadline.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	deadline time.Time
+	f        func()
+	ch       chan time.Time
+	stopped  bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.add(t, d)
+
+	return t.ch
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{clock: c, f: f}
+	c.add(t, d)
+
+	return t
+}
+
+func (c *fakeClock) add(t *fakeTimer, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t.deadline = c.now.Add(d)
+	c.timers = append(c.timers, t)
+}
+
+// waiters returns the number of timers not yet fired or stopped.
+func (c *fakeClock) waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, t := range c.timers {
+		if !t.stopped {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Advance moves the time forward, and fires the timers due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+
+	var due []*fakeTimer
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.deadline.After(c.now):
+			due = append(due, t)
+		default:
+			timers = append(timers, t)
+		}
+	}
+	c.timers = timers
+	now := c.now
+	c.mu.Unlock()
+
+	for _, t := range due {
+		if t.f != nil {
+			t.f()
+			continue
+		}
+
+		t.ch <- now
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := !t.stopped
+	t.stopped = true
+
+	return active
+}
+
+// fakePublisher records the published task states, and fails the first failures calls.
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	calls     int
+	published []string
+}
+
+func (p *fakePublisher) Publish(_ context.Context, task *rctypes.Task[any, any], _ bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("nats: timeout")
+	}
+
+	p.published = append(p.published, task.Status.Last())
+	return nil
+}
+
+func (p *fakePublisher) statuses() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string{}, p.published...)
+}
+
+func newTask(state rctypes.State, status string) *rctypes.Task[any, any] {
+	task := &rctypes.Task[any, any]{State: state}
+	task.Status.Append(status)
+
+	return task
+}
+
+func newTestPublisher(t *testing.T, pub *fakePublisher) (*StatusPublisher, *fakeClock) {
+	t.Helper()
+
+	clock := newFakeClock()
+	cfg := &Config{MinInterval: 2 * time.Second, RetryAttempts: 3, RetryInterval: time.Second, MaxRetryInterval: 4 * time.Second}
+
+	p, err := NewWithClock(pub, cfg, clock, logrus.NewEntry(logrus.New()))
+	assert.NoError(t, err)
+
+	return p, clock
+}
+
+func TestPublishCoalescesUpdates(t *testing.T) {
+	pub := &fakePublisher{}
+	p, clock := newTestPublisher(t, pub)
+	ctx := context.Background()
+
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "one")))
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "two")))
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "three")))
+
+	// the first update is sent, the later ones are held until the interval elapsed
+	assert.Equal(t, []string{"one"}, pub.statuses())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, []string{"one"}, pub.statuses())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, []string{"one", "three"}, pub.statuses())
+
+	// an update after the interval is sent immediately
+	clock.Advance(2 * time.Second)
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "four")))
+	assert.Equal(t, []string{"one", "three", "four"}, pub.statuses())
+}
+
+func TestPublishFinalStateImmediately(t *testing.T) {
+	pub := &fakePublisher{}
+	p, clock := newTestPublisher(t, pub)
+	ctx := context.Background()
+
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "one")))
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "two")))
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Succeeded, "done")))
+
+	assert.Equal(t, []string{"one", "done"}, pub.statuses())
+
+	// the coalesced update is discarded, and never overwrites the final state
+	clock.Advance(time.Minute)
+	assert.Equal(t, []string{"one", "done"}, pub.statuses())
+	assert.Equal(t, 0, clock.waiters())
+}
+
+func TestPublishRetries(t *testing.T) {
+	pub := &fakePublisher{failures: 2}
+	p, clock := newTestPublisher(t, pub)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- p.Publish(context.Background(), newTask(rctypes.Failed, "failed"))
+	}()
+
+	// first retry after 1s, the second after 2s
+	for _, d := range []time.Duration{time.Second, 2 * time.Second} {
+		assert.Eventually(t, func() bool { return clock.waiters() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(d)
+	}
+
+	assert.NoError(t, <-errCh)
+	assert.Equal(t, []string{"failed"}, pub.statuses())
+	assert.Equal(t, 3, pub.calls)
+}
+
+func TestPublishRetriesExhausted(t *testing.T) {
+	pub := &fakePublisher{failures: 5}
+	p, clock := newTestPublisher(t, pub)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- p.Publish(context.Background(), newTask(rctypes.Failed, "failed"))
+	}()
+
+	for i := 0; i < 2; i++ {
+		assert.Eventually(t, func() bool { return clock.waiters() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(4 * time.Second)
+	}
+
+	assert.ErrorIs(t, <-errCh, ErrPublish)
+	assert.Equal(t, 3, pub.calls)
+	assert.Empty(t, pub.statuses())
+}
+
+func TestFlush(t *testing.T) {
+	pub := &fakePublisher{}
+	p, clock := newTestPublisher(t, pub)
+	ctx := context.Background()
+
+	assert.NoError(t, p.Flush())
+
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "one")))
+	assert.NoError(t, p.Publish(ctx, newTask(rctypes.Active, "two")))
+	assert.NoError(t, p.Flush())
+
+	assert.Equal(t, []string{"one", "two"}, pub.statuses())
+	assert.Equal(t, 0, clock.waiters())
+}
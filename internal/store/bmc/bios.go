@@ -0,0 +1,244 @@
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/metal-toolbox/bmclib/constants"
+	"github.com/pkg/errors"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const (
+	// Dell iDRAC action to discard the BIOS settings pending on the next boot.
+	dellClearPendingAction = "/Actions/Oem/DellManager.ClearPending"
+
+	// Redfish Bios.ChangePassword PasswordName of the BIOS setup password.
+	biosAdminPasswordName = "AdminPassword"
+	dellSetupPasswordName = "SetupPassword"
+)
+
+var (
+	errNoSystem = errors.New("no computer system found")
+)
+
+// PendingBiosSettings returns the BIOS settings staged on the BMC, to be applied on the next boot.
+func (b *Client) PendingBiosSettings(ctx context.Context) (map[string]string, error) {
+	defer b.tracelog()
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending map[string]string
+	err = b.retry(ctx, "PendingBiosSettings", true, func(context.Context) error {
+		_, _, attrs, err := pendingBiosAttributes(rf)
+		if err != nil {
+			return err
+		}
+
+		pending = make(map[string]string, len(attrs))
+		for name, value := range attrs {
+			pending[name] = fmt.Sprint(value)
+		}
+
+		return nil
+	})
+
+	return pending, err
+}
+
+// ClearPendingBiosSettings discards the BIOS settings staged on the BMC, with the Dell ClearPending action,
+// or by deleting the settings resource. BMCs which support neither fail with the ErrorClassUnsupported class.
+func (b *Client) ClearPendingBiosSettings(ctx context.Context) error {
+	defer b.tracelog()
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return b.retry(ctx, "ClearPendingBiosSettings", true, func(context.Context) error {
+		_, settingsURI, attrs, err := pendingBiosAttributes(rf)
+		if err != nil {
+			return err
+		}
+
+		if len(attrs) == 0 {
+			return nil
+		}
+
+		if strings.EqualFold(b.asset.Vendor, constants.Dell) {
+			resp, err := rf.Post(settingsURI+dellClearPendingAction, struct{}{})
+			if err != nil {
+				return err
+			}
+
+			return resp.Body.Close()
+		}
+
+		return deleteBiosSettings(rf, settingsURI)
+	})
+}
+
+// deleteBiosSettings deletes the BIOS settings resource, discarding the staged settings,
+// unless the resource does not allow it according to its Allow header.
+func deleteBiosSettings(rf *gofish.APIClient, settingsURI string) error {
+	resp, err := rf.Get(settingsURI)
+	if err != nil {
+		return err
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		return err
+	}
+
+	if allow := resp.Header.Get("Allow"); allow != "" && !strings.Contains(strings.ToUpper(allow), http.MethodDelete) {
+		return errors.Wrap(errBMCNotImplemented, "clearing pending bios settings, "+settingsURI+" allows "+allow)
+	}
+
+	// BMCs not supporting the deletion respond with 405 Method Not Allowed
+	resp, err = rf.Delete(settingsURI)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// SetBiosPassword sets the BIOS setup password, current is empty when no password is set,
+// the passwords are not included in any returned error or log.
+func (b *Client) SetBiosPassword(ctx context.Context, current, password string) error {
+	defer b.tracelog()
+
+	name := biosAdminPasswordName
+	if strings.EqualFold(b.asset.Vendor, constants.Dell) {
+		name = dellSetupPasswordName
+	}
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// a retry after a successful change would fail with the old password
+	return b.retry(ctx, "SetBiosPassword", false, func(context.Context) error {
+		bios, err := systemBios(rf)
+		if err != nil {
+			return err
+		}
+
+		return bios.ChangePassword(name, current, password)
+	})
+}
+
+// computerSystem returns the first computer system managed by the BMC.
+func computerSystem(rf *gofish.APIClient) (*redfish.ComputerSystem, error) {
+	systems, err := rf.Service.Systems()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(systems) == 0 {
+		return nil, errNoSystem
+	}
+
+	return systems[0], nil
+}
+
+// pendingBiosAttributes returns the current BIOS resource, the URI of its settings resource,
+// and the attributes in the settings resource which differ from the current values.
+func pendingBiosAttributes(rf *gofish.APIClient) (*redfish.Bios, string, redfish.SettingsAttributes, error) {
+	system, err := computerSystem(rf)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	bios, err := system.Bios()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if bios == nil {
+		return nil, "", nil, errors.Wrap(errBMCNotImplemented, "no Bios resource")
+	}
+
+	settings, err := biosSettingsAnnotation(rf, bios.ODataID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	settingsURI := settings.URI
+
+	staged := struct {
+		Attributes redfish.SettingsAttributes `json:"Attributes"`
+	}{}
+
+	if err := getJSON(rf, settingsURI, &staged); err != nil {
+		return nil, "", nil, err
+	}
+
+	pending := redfish.SettingsAttributes{}
+	for name, value := range staged.Attributes {
+		if fmt.Sprint(bios.Attributes[name]) != fmt.Sprint(value) {
+			pending[name] = value
+		}
+	}
+
+	return bios, settingsURI, pending, nil
+}
+
+// biosSettings is the @Redfish.Settings annotation of the BIOS resource.
+type biosSettings struct {
+	// URI of the resource the BIOS settings are staged on.
+	URI string
+	// SupportedApplyTimes lists the apply times the BMC accepts for staged settings, empty when not advertised.
+	SupportedApplyTimes []string
+}
+
+// biosSettingsAnnotation returns the BIOS settings from the @Redfish.Settings annotation,
+// implementations without the annotation stage settings on the Bios/Settings resource.
+func biosSettingsAnnotation(rf *gofish.APIClient, biosURI string) (*biosSettings, error) {
+	annotation := struct {
+		Settings struct {
+			SettingsObject struct {
+				ODataID string `json:"@odata.id"`
+			} `json:"SettingsObject"`
+			SupportedApplyTimes []string `json:"SupportedApplyTimes"`
+		} `json:"@Redfish.Settings"`
+	}{}
+
+	if err := getJSON(rf, biosURI, &annotation); err != nil {
+		return nil, err
+	}
+
+	settings := &biosSettings{
+		URI:                 annotation.Settings.SettingsObject.ODataID,
+		SupportedApplyTimes: annotation.Settings.SupportedApplyTimes,
+	}
+
+	if settings.URI == "" {
+		settings.URI = strings.TrimSuffix(biosURI, "/") + "/Settings"
+	}
+
+	return settings, nil
+}
+
+func getJSON(rf *gofish.APIClient, uri string, v interface{}) error {
+	resp, err := rf.Get(uri)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "invalid response from "+uri)
+	}
+
+	return nil
+}
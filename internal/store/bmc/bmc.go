@@ -8,24 +8,31 @@ import (
 	"net/http/cookiejar"
 	"path"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
 	logrusr "github.com/bombsimon/logrusr/v4"
+	"github.com/jacobweinstock/registrar"
 	"github.com/metal-toolbox/bmclib"
-	"github.com/metal-toolbox/bmclib/constants"
-	"github.com/metal-toolbox/bmclib/providers"
+	"github.com/metal-toolbox/bmclib/providers/redfish"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/stmcginnis/gofish"
 	"go.opentelemetry.io/otel"
 	"golang.org/x/net/publicsuffix"
 
 	"github.com/metal-toolbox/bioscfg/internal/model"
 )
 
+// default client parameters, see ClientConfig
 const (
-	logoutTimeout = 1 * time.Minute
-	loginTimeout  = 1 * time.Minute
+	logoutTimeout  = 1 * time.Minute
+	loginTimeout   = 1 * time.Minute
+	httpTimeout    = 600 * time.Second
+	connectTimeout = 180 * time.Second
+
+	httpsPort = "443"
 )
 
 var (
@@ -36,20 +43,55 @@ var (
 
 // Bmc is an implementation of the Queryor interface
 type Client struct {
-	client *bmclib.Client
-	asset  *model.Asset
-	logger *logrus.Entry
+	client     *bmclib.Client
+	httpClient *http.Client
+	rf         *gofish.APIClient
+	asset      *model.Asset
+	cfg        *ClientConfig
+	recovery   *RecoveryConfig
+	resets     ResetLimiter
+	recovering bool
+	// facility is the facility the condition was received for, the metrics are labelled with
+	facility string
+	logger   *logrus.Entry
 }
 
-// NewBMCClient creates a new Queryor interface for a BMC
-func NewBMCClient(asset *model.Asset, logger *logrus.Entry) *Client {
-	client := newBmclibClient(asset, logger)
+// NewBMCClient creates a new Queryor interface for a BMC, the configuration validated at startup is not modified.
+// The resets limiter is required for the BMC recovery, and may be nil when recovery is disabled.
+// The facility is the facility the condition was received for, the TLS overrides are matched and the metrics labelled with.
+func NewBMCClient(asset *model.Asset, cfg *Config, resets ResetLimiter, facility string, logger *logrus.Entry) (*Client, error) {
+	tlsConfig, err := newTLSConfig(&cfg.TLS, asset, facility)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCfg := cfg.clientConfig(asset.Vendor, asset.Model)
+	httpClient := newHTTPClient(clientCfg, tlsConfig)
 
 	return &Client{
-		client,
-		asset,
-		logger,
-	}
+		client:     newBmclibClient(asset, clientCfg, httpClient, logger),
+		httpClient: httpClient,
+		asset:      asset,
+		cfg:        clientCfg,
+		recovery:   &cfg.Recovery,
+		resets:     resets,
+		facility:   facility,
+		logger:     logger,
+	}, nil
+}
+
+// CheckReachable verifies the BMC accepts TCP connections on the HTTPS port
+func (b *Client) CheckReachable(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: b.cfg.ConnectTimeout}
+
+	return b.retry(ctx, "CheckReachable", true, func(ctx context.Context) error {
+		conn, err := dialer.DialContext(ctx, "tcp", b.asset.BmcAddress.HostPort(httpsPort))
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	})
 }
 
 // Open creates a BMC session
@@ -59,7 +101,7 @@ func (b *Client) Open(ctx context.Context) error {
 	}
 	defer b.tracelog()
 
-	return b.client.Open(ctx)
+	return b.retry(ctx, "Open", true, b.client.Open)
 }
 
 // Close logs out of the BMC
@@ -68,11 +110,13 @@ func (b *Client) Close(traceCtx context.Context) error {
 		return nil
 	}
 
-	ctxClose, cancel := context.WithTimeout(traceCtx, logoutTimeout)
+	ctxClose, cancel := context.WithTimeout(traceCtx, b.cfg.LogoutTimeout)
 	defer cancel()
 
 	defer b.tracelog()
 
+	b.closeRedfish()
+
 	if err := b.client.Close(ctxClose); err != nil {
 		return errors.Wrap(errBMCLogout, err.Error())
 	}
@@ -80,33 +124,58 @@ func (b *Client) Close(traceCtx context.Context) error {
 	return nil
 }
 
-// GetPowerState returns the device power status
-func (b *Client) GetPowerState(ctx context.Context) (string, error) {
+// GetPowerState returns the device power status, normalized from the provider power state
+func (b *Client) GetPowerState(ctx context.Context) (model.PowerState, error) {
 	defer b.tracelog()
 
-	return b.client.GetPowerState(ctx)
+	state := model.PowerStateUnknown
+	err := b.retry(ctx, "GetPowerState", true, func(ctx context.Context) error {
+		raw, err := b.client.GetPowerState(ctx)
+		if err != nil {
+			return err
+		}
+
+		state = model.ParsePowerState(raw)
+		if state == model.PowerStateUnknown {
+			b.logger.WithField("state", raw).Warn("unknown power state")
+		}
+
+		return nil
+	})
+
+	return state, err
 }
 
-// SetPowerState sets the given power state on the device
-func (b *Client) SetPowerState(ctx context.Context, state string) error {
+// SetPowerState runs the given power action on the device
+func (b *Client) SetPowerState(ctx context.Context, action model.PowerAction) error {
 	defer b.tracelog()
-	_, err := b.client.SetPowerState(ctx, state)
-	return err
+
+	return b.retry(ctx, "SetPowerState", false, func(ctx context.Context) error {
+		_, err := b.client.SetPowerState(ctx, string(action))
+		return err
+	})
 }
 
 // SetBootDevice sets the boot device of the remote device, and validates it was set
 //
 //nolint:gocritic // its a TODO
 func (b *Client) SetBootDevice(ctx context.Context, device string, persistent, efiBoot bool) error {
-	ok, err := b.client.SetBootDevice(ctx, device, persistent, efiBoot)
+	err := b.retry(ctx, "SetBootDevice", true, func(ctx context.Context) error {
+		ok, err := b.client.SetBootDevice(ctx, device, persistent, efiBoot)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return errors.New("setting boot device failed")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if !ok {
-		return errors.New("setting boot device failed")
-	}
-
 	// Now lets validate the boot device order
 	// TODO; This is a WIP. We do not know yet if This is the right bmc call to get boot device
 	// override, err := b.client.GetBootDeviceOverride(ctx)
@@ -131,33 +200,83 @@ func (b *Client) SetBootDevice(ctx context.Context, device string, persistent, e
 
 // GetBootDevice gets the boot device information of the remote device
 func (b *Client) GetBootDevice(_ context.Context) (device string, persistent, efiBoot bool, err error) {
-	return "", false, false, errors.Wrap(errBMCNotImplemented, "GetBootDevice")
+	return "", false, false, newError("GetBootDevice", errors.Wrap(errBMCNotImplemented, "GetBootDevice"))
 }
 
 // PowerCycleBMC sets a power cycle action on the BMC of the remote device
 func (b *Client) PowerCycleBMC(ctx context.Context) error {
 	defer b.tracelog()
-	_, err := b.client.ResetBMC(ctx, "GracefulRestart")
-	return err
+
+	return b.retry(ctx, "PowerCycleBMC", false, func(ctx context.Context) error {
+		_, err := b.client.ResetBMC(ctx, "GracefulRestart")
+		return err
+	})
 }
 
 func (b *Client) HostBooted(ctx context.Context) (bool, error) {
-	defer b.tracelog()
-	status, _, err := b.client.PostCode(ctx)
+	progress, err := b.BootProgress(ctx)
 	if err != nil {
 		return false, err
 	}
-	return status == constants.POSTStateOS, nil
+
+	return progress.Booted(), nil
+}
+
+// BootProgress returns the POST state and code of the host
+func (b *Client) BootProgress(ctx context.Context) (*BootProgress, error) {
+	defer b.tracelog()
+
+	progress := &BootProgress{}
+	err := b.retry(ctx, "BootProgress", true, func(ctx context.Context) error {
+		var err error
+		progress.State, progress.Code, err = b.client.PostCode(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, nil
 }
 
+// BiosSettings returns the current BIOS settings.
+func (b *Client) BiosSettings(ctx context.Context) (map[string]string, error) {
+	defer b.tracelog()
+
+	var settings map[string]string
+	err := b.retry(ctx, "BiosSettings", true, func(ctx context.Context) error {
+		var err error
+		settings, err = b.client.GetBiosConfiguration(ctx)
+		return err
+	})
+
+	return settings, err
+}
+
+// ResetBiosConfig resets the BIOS settings to their defaults, not retried once sent since on Dell each call queues a BIOS job.
 func (b *Client) ResetBiosConfig(ctx context.Context) error {
 	defer b.tracelog()
-	return b.client.ResetBiosConfiguration(ctx)
+
+	return b.retry(ctx, "ResetBiosConfig", false, b.client.ResetBiosConfiguration)
 }
 
+// SetBiosConfigFromFile applies the BIOS config, not retried once sent since on Dell each call queues a BIOS job.
 func (b *Client) SetBiosConfigFromFile(ctx context.Context, cfg string) error {
 	defer b.tracelog()
-	return b.client.SetBiosConfigurationFromFile(ctx, cfg)
+
+	return b.retry(ctx, "SetBiosConfigFromFile", false, func(ctx context.Context) error {
+		return b.client.SetBiosConfigurationFromFile(ctx, cfg)
+	})
+}
+
+// SupportedProviders returns the names of the opened providers which support all the given features
+func (b *Client) SupportedProviders(features ...Feature) []string {
+	names := []string{}
+	for _, driver := range b.client.Registry.Supports(features...) {
+		names = append(names, driver.Name)
+	}
+
+	return names
 }
 
 func (b *Client) tracelog() {
@@ -187,33 +306,31 @@ func (b *Client) tracelog() {
 		}).Trace(funcName + ": connection metadata")
 }
 
-func newHTTPClient() *http.Client {
+func newHTTPClient(cfg *ClientConfig, tlsConfig *tls.Config) *http.Client {
 	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	if err != nil {
 		panic(err)
 	}
 
-	// nolint:gomnd // time duration declarations are clear as is.
 	return &http.Client{
-		Timeout: time.Second * 600,
+		Timeout: cfg.HTTPTimeout,
 		Jar:     jar,
 		Transport: &http.Transport{
-			// nolint:gosec // BMCs don't have valid certs.
-			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			TLSClientConfig:   tlsConfig,
 			DisableKeepAlives: true,
 			Dial: (&net.Dialer{
-				Timeout:   180 * time.Second,
-				KeepAlive: 180 * time.Second,
+				Timeout:   cfg.ConnectTimeout,
+				KeepAlive: cfg.ConnectTimeout,
 			}).Dial,
-			TLSHandshakeTimeout:   180 * time.Second,
-			ResponseHeaderTimeout: 600 * time.Second,
-			IdleConnTimeout:       180 * time.Second,
+			TLSHandshakeTimeout:   cfg.ConnectTimeout,
+			ResponseHeaderTimeout: cfg.HTTPTimeout,
+			IdleConnTimeout:       cfg.ConnectTimeout,
 		},
 	}
 }
 
 // newBmclibClient initializes a bmclib client with the given credentials
-func newBmclibClient(asset *model.Asset, l *logrus.Entry) *bmclib.Client {
+func newBmclibClient(asset *model.Asset, cfg *ClientConfig, httpClient *http.Client, l *logrus.Entry) *bmclib.Client {
 	logger := logrus.New()
 	logger.Formatter = l.Logger.Formatter
 
@@ -231,22 +348,62 @@ func newBmclibClient(asset *model.Asset, l *logrus.Entry) *bmclib.Client {
 
 	logruslogr := logrusr.New(logger)
 
-	bmcClient := bmclib.NewClient(
-		asset.BmcAddress.String(),
-		asset.BmcUsername,
-		asset.BmcPassword,
+	opts := []bmclib.Option{
 		bmclib.WithLogger(logruslogr),
-		bmclib.WithHTTPClient(newHTTPClient()),
-		bmclib.WithPerProviderTimeout(loginTimeout),
+		bmclib.WithHTTPClient(httpClient),
+		bmclib.WithPerProviderTimeout(cfg.LoginTimeout),
 		bmclib.WithRedfishEtagMatchDisabled(true),
 		bmclib.WithTracerProvider(otel.GetTracerProvider()),
-	)
+	}
+
+	if asset.BmcAddress.Port != "" {
+		opts = append(opts, bmclib.WithRedfishPort(asset.BmcAddress.Port))
+	}
 
-	bmcClient.Registry.Drivers = bmcClient.Registry.Supports(
-		providers.FeatureResetBiosConfiguration,
-		providers.FeatureSetBiosConfiguration,
-		providers.FeatureSetBiosConfigurationFromFile,
+	bmcClient := bmclib.NewClient(
+		asset.BmcAddress.URLHost(),
+		asset.BmcUsername,
+		asset.BmcPassword,
+		opts...,
 	)
 
+	bmcClient.Registry.Drivers = biosProviders(bmcClient.Registry)
+
+	// the vendor providers always connect on the default port
+	if asset.BmcAddress.Port != "" && asset.BmcAddress.Port != httpsPort {
+		bmcClient.Registry.Drivers = bmcClient.Registry.For(redfish.ProviderName)
+	}
+	bmcClient.Registry.Drivers = orderProviders(bmcClient.Registry, cfg.PreferredProviders, cfg.ExcludedProviders)
+
 	return bmcClient
 }
+
+// biosProviders returns the drivers supporting any of the BIOS configuration features
+func biosProviders(registry *registrar.Registry) registrar.Drivers {
+	drivers := registrar.Drivers{}
+
+	for _, driver := range registry.Drivers {
+		if slices.ContainsFunc(driver.Features, func(f registrar.Feature) bool {
+			return f == FeatureResetBiosConfig || f == FeatureSetBiosConfig || f == FeatureSetBiosConfigFromFile
+		}) {
+			drivers = append(drivers, driver)
+		}
+	}
+
+	return drivers
+}
+
+// orderProviders drops the excluded drivers from the registry and moves the preferred drivers to the front.
+func orderProviders(registry *registrar.Registry, preferred, excluded []string) registrar.Drivers {
+	drivers := registrar.Drivers{}
+
+	for _, driver := range registry.Drivers {
+		if slices.ContainsFunc(excluded, func(name string) bool { return strings.EqualFold(name, driver.Name) }) {
+			continue
+		}
+
+		drivers = append(drivers, driver)
+	}
+
+	return registrar.Registry{Drivers: drivers}.PreferDriver(preferred...)
+}
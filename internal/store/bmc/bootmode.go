@@ -0,0 +1,162 @@
+package bmc
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/metal-toolbox/bmclib/constants"
+	"github.com/pkg/errors"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// BootMode is the firmware boot mode of the server.
+type BootMode string
+
+const (
+	BootModeUEFI    BootMode = "uefi"
+	BootModeLegacy  BootMode = "legacy"
+	BootModeUnknown BootMode = "unknown"
+)
+
+// ParseBootMode returns the BootMode for the given value, BootModeUnknown is returned for unknown values.
+func ParseBootMode(s string) BootMode {
+	switch BootMode(strings.ToLower(s)) {
+	case BootModeUEFI:
+		return BootModeUEFI
+	case BootModeLegacy:
+		return BootModeLegacy
+	default:
+		return BootModeUnknown
+	}
+}
+
+// bootModeAttribute is the vendor BIOS attribute, and values for the boot mode.
+type bootModeAttribute struct {
+	name   string
+	uefi   string
+	legacy string
+}
+
+func bootModeAttributeFor(vendor string) bootModeAttribute {
+	switch {
+	case strings.EqualFold(vendor, constants.Dell):
+		return bootModeAttribute{name: "BootMode", uefi: "Uefi", legacy: "Bios"}
+	case strings.HasPrefix(strings.ToLower(vendor), strings.ToLower(constants.HP)):
+		return bootModeAttribute{name: "BootMode", uefi: "Uefi", legacy: "LegacyBios"}
+	case strings.EqualFold(vendor, constants.Supermicro):
+		return bootModeAttribute{name: "BootModeSelect", uefi: "UEFI", legacy: "LEGACY"}
+	default:
+		return bootModeAttribute{name: "BootMode", uefi: "Uefi", legacy: "Legacy"}
+	}
+}
+
+func (a bootModeAttribute) mode(value string) BootMode {
+	switch {
+	case strings.EqualFold(value, a.uefi):
+		return BootModeUEFI
+	case strings.EqualFold(value, a.legacy):
+		return BootModeLegacy
+	default:
+		return BootModeUnknown
+	}
+}
+
+// GetBootMode returns the current firmware boot mode of the server.
+func (b *Client) GetBootMode(ctx context.Context) (BootMode, error) {
+	defer b.tracelog()
+
+	attr := bootModeAttributeFor(b.asset.Vendor)
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return BootModeUnknown, err
+	}
+
+	mode := BootModeUnknown
+	err = b.retry(ctx, "GetBootMode", true, func(context.Context) error {
+		bios, err := systemBios(rf)
+		if err != nil {
+			return err
+		}
+
+		value, ok := bios.Attributes[attr.name]
+		if !ok {
+			return errors.Wrap(errBMCNotImplemented, "no BIOS attribute "+attr.name)
+		}
+
+		mode = attr.mode(bios.Attributes.String(attr.name))
+		if mode == BootModeUnknown {
+			b.logger.WithField("value", value).Debug("unknown boot mode attribute value")
+		}
+
+		return nil
+	})
+
+	return mode, err
+}
+
+// SetBootMode stages the firmware boot mode, the change is applied on the next boot,
+// not retried once sent since on Dell each call queues a BIOS job.
+func (b *Client) SetBootMode(ctx context.Context, mode BootMode) error {
+	defer b.tracelog()
+
+	attr := bootModeAttributeFor(b.asset.Vendor)
+
+	var value string
+	switch mode {
+	case BootModeUEFI:
+		value = attr.uefi
+	case BootModeLegacy:
+		value = attr.legacy
+	default:
+		return newError("SetBootMode", errors.New("invalid boot mode: "+string(mode)))
+	}
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return b.retry(ctx, "SetBootMode", false, func(context.Context) error {
+		bios, err := systemBios(rf)
+		if err != nil {
+			return err
+		}
+
+		settings, err := biosSettingsAnnotation(rf, bios.ODataID)
+		if err != nil {
+			return err
+		}
+
+		// request the change is applied on reset, when the BMC advertises the apply time
+		attrs := redfish.SettingsAttributes{attr.name: value}
+		if slices.Contains(settings.SupportedApplyTimes, string(common.OnResetApplyTime)) {
+			return bios.UpdateBiosAttributesApplyAt(attrs, common.OnResetApplyTime)
+		}
+
+		return bios.UpdateBiosAttributes(attrs)
+	})
+}
+
+// systemBios returns the Bios resource of the computer system, it is called within the retried operations
+// with the session opened before, for the attempts not to be multiplied by the session open retries.
+func systemBios(rf *gofish.APIClient) (*redfish.Bios, error) {
+	system, err := computerSystem(rf)
+	if err != nil {
+		return nil, err
+	}
+
+	bios, err := system.Bios()
+	if err != nil {
+		return nil, err
+	}
+
+	if bios == nil {
+		return nil, errors.Wrap(errBMCNotImplemented, "no Bios resource")
+	}
+
+	return bios, nil
+}
@@ -0,0 +1,31 @@
+package bmc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootModeAttribute(t *testing.T) {
+	cases := []struct {
+		vendor   string
+		value    string
+		wantAttr string
+		want     BootMode
+	}{
+		{"Dell", "Uefi", "BootMode", BootModeUEFI},
+		{"dell", "Bios", "BootMode", BootModeLegacy},
+		{"HPE", "LegacyBios", "BootMode", BootModeLegacy},
+		{"Supermicro", "UEFI", "BootModeSelect", BootModeUEFI},
+		{"Supermicro", "DUAL", "BootModeSelect", BootModeUnknown},
+		{"Quanta", "Legacy", "BootMode", BootModeLegacy},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.vendor+"/"+tc.value, func(t *testing.T) {
+			attr := bootModeAttributeFor(tc.vendor)
+			assert.Equal(t, tc.wantAttr, attr.name)
+			assert.Equal(t, tc.want, attr.mode(tc.value))
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package bmc
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type TLSMode string
+
+const (
+	// TLSModeInsecure skips verification of the BMC certificate chain.
+	TLSModeInsecure TLSMode = "insecure"
+
+	// TLSModeVerify verifies the BMC certificate chain against the system roots and the configured CA bundle.
+	TLSModeVerify TLSMode = "verify"
+)
+
+var (
+	ErrBMCConfig = errors.New("bmc configuration error")
+)
+
+// Config defines configuration for the BMC client connections.
+type Config struct {
+	TLS TLSConfig `mapstructure:"tls"`
+
+	// Client holds the client parameters applied to all BMCs.
+	Client ClientConfig `mapstructure:"client"`
+
+	// Overrides set client parameters per vendor, or vendor and model.
+	Overrides []ClientOverride `mapstructure:"overrides"`
+
+	// Recovery configures the BMC reset when the BMC is unresponsive.
+	Recovery RecoveryConfig `mapstructure:"recovery"`
+}
+
+// RecoveryConfig defines the opt-in recovery of a BMC which repeatedly times out, or returns server errors.
+type RecoveryConfig struct {
+	// Enabled allows the BMC to be reset once the retries of an operation are exhausted.
+	Enabled bool `mapstructure:"enabled"`
+
+	// MaxResetsPerDay caps the BMC resets per asset in a day (UTC).
+	MaxResetsPerDay int `mapstructure:"max_resets_per_day"`
+
+	// Timeout is the maximum time to wait for the BMC to return after a reset.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// ClientConfig defines the BMC client timeouts and bmclib provider selection,
+// unset fields are inherited from the defaults.
+type ClientConfig struct {
+	// HTTPTimeout is the overall request and response header timeout.
+	HTTPTimeout time.Duration `mapstructure:"http_timeout"`
+
+	// ConnectTimeout is the dial, TLS handshake and idle connection timeout.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+
+	// LoginTimeout is the per provider timeout when opening a BMC session.
+	LoginTimeout time.Duration `mapstructure:"login_timeout"`
+
+	// LogoutTimeout is the timeout when closing the BMC session.
+	LogoutTimeout time.Duration `mapstructure:"logout_timeout"`
+
+	// PreferredProviders are the bmclib providers to be tried first, in the given order.
+	PreferredProviders []string `mapstructure:"preferred_providers"`
+
+	// ExcludedProviders are the bmclib providers never to be used.
+	ExcludedProviders []string `mapstructure:"excluded_providers"`
+
+	// RetryAttempts is the number of times an operation failing with a transient error is attempted,
+	// setting this to 1 disables retries.
+	RetryAttempts int `mapstructure:"retry_attempts"`
+
+	// RetryInterval is the initial interval between attempts, doubled after each attempt.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+
+	// MaxRetryInterval caps the interval between attempts.
+	MaxRetryInterval time.Duration `mapstructure:"max_retry_interval"`
+}
+
+// ClientOverride sets the client parameters for assets matching the vendor and the optional model.
+//
+// An override matching on both vendor and model is applied over one matching the vendor only.
+type ClientOverride struct {
+	Vendor       string `mapstructure:"vendor"`
+	Model        string `mapstructure:"model"`
+	ClientConfig `mapstructure:",squash"`
+}
+
+// TLSConfig defines how BMC certificates are verified.
+type TLSConfig struct {
+	// CABundle is the path to a PEM encoded bundle of CA certificates trusted in addition to the system roots.
+	CABundle string `mapstructure:"ca_bundle"`
+
+	// Mode is the default verification mode, defaults to insecure.
+	Mode TLSMode `mapstructure:"mode"`
+
+	// Overrides set the verification mode per vendor and/or facility.
+	Overrides []TLSOverride `mapstructure:"overrides"`
+}
+
+// TLSOverride sets the verification mode for assets matching the given vendor and/or facility.
+//
+// When multiple overrides match an asset, the one matching on both vendor and facility wins.
+type TLSOverride struct {
+	Vendor   string  `mapstructure:"vendor"`
+	Facility string  `mapstructure:"facility"`
+	Mode     TLSMode `mapstructure:"mode"`
+}
+
+// Validate checks the BMC configuration once at startup, the clients then treat it as read-only.
+func (cfg *Config) Validate() error {
+	if cfg == nil {
+		return errors.Wrap(ErrBMCConfig, "config was nil")
+	}
+
+	if err := cfg.TLS.Mode.validate(); err != nil {
+		return err
+	}
+
+	for _, o := range cfg.TLS.Overrides {
+		if o.Vendor == "" && o.Facility == "" {
+			return errors.Wrap(ErrBMCConfig, "tls override requires a vendor or facility")
+		}
+
+		if err := o.Mode.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.Client.validate(); err != nil {
+		return err
+	}
+
+	for i := range cfg.Overrides {
+		if cfg.Overrides[i].Vendor == "" {
+			return errors.Wrap(ErrBMCConfig, "client override requires a vendor")
+		}
+
+		if err := cfg.Overrides[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Recovery.MaxResetsPerDay < 0 || cfg.Recovery.Timeout < 0 {
+		return errors.Wrap(ErrBMCConfig, "negative recovery max resets or timeout")
+	}
+
+	if cfg.TLS.CABundle != "" {
+		if _, err := os.Stat(cfg.TLS.CABundle); err != nil {
+			return errors.Wrap(ErrBMCConfig, "tls ca bundle: "+err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (m TLSMode) validate() error {
+	switch m {
+	case "", TLSModeInsecure, TLSModeVerify:
+		return nil
+	default:
+		return errors.Wrap(ErrBMCConfig, "invalid tls mode: "+string(m))
+	}
+}
+
+func (c *ClientConfig) validate() error {
+	for _, d := range []time.Duration{c.HTTPTimeout, c.ConnectTimeout, c.LoginTimeout, c.LogoutTimeout, c.RetryInterval, c.MaxRetryInterval} {
+		if d < 0 {
+			return errors.Wrap(ErrBMCConfig, "negative client timeout: "+d.String())
+		}
+	}
+
+	if c.RetryAttempts < 0 {
+		return errors.Wrap(ErrBMCConfig, "negative client retry attempts")
+	}
+
+	return nil
+}
+
+// merge sets the fields defined in the given ClientConfig.
+func (c *ClientConfig) merge(o *ClientConfig) {
+	if o.HTTPTimeout != 0 {
+		c.HTTPTimeout = o.HTTPTimeout
+	}
+
+	if o.ConnectTimeout != 0 {
+		c.ConnectTimeout = o.ConnectTimeout
+	}
+
+	if o.LoginTimeout != 0 {
+		c.LoginTimeout = o.LoginTimeout
+	}
+
+	if o.LogoutTimeout != 0 {
+		c.LogoutTimeout = o.LogoutTimeout
+	}
+
+	if len(o.PreferredProviders) > 0 {
+		c.PreferredProviders = o.PreferredProviders
+	}
+
+	if len(o.ExcludedProviders) > 0 {
+		c.ExcludedProviders = o.ExcludedProviders
+	}
+
+	if o.RetryAttempts != 0 {
+		c.RetryAttempts = o.RetryAttempts
+	}
+
+	if o.RetryInterval != 0 {
+		c.RetryInterval = o.RetryInterval
+	}
+
+	if o.MaxRetryInterval != 0 {
+		c.MaxRetryInterval = o.MaxRetryInterval
+	}
+}
+
+// clientConfig returns the client parameters for the given vendor and model.
+func (cfg *Config) clientConfig(vendor, model string) *ClientConfig {
+	c := &ClientConfig{
+		HTTPTimeout:      httpTimeout,
+		ConnectTimeout:   connectTimeout,
+		LoginTimeout:     loginTimeout,
+		LogoutTimeout:    logoutTimeout,
+		RetryAttempts:    retryAttempts,
+		RetryInterval:    retryInterval,
+		MaxRetryInterval: maxRetryInterval,
+	}
+
+	c.merge(&cfg.Client)
+
+	// vendor overrides are applied before vendor, model overrides
+	for _, withModel := range []bool{false, true} {
+		for i := range cfg.Overrides {
+			o := &cfg.Overrides[i]
+			if (o.Model != "") != withModel || !strings.EqualFold(o.Vendor, vendor) {
+				continue
+			}
+
+			if withModel && !strings.EqualFold(o.Model, model) {
+				continue
+			}
+
+			c.merge(&o.ClientConfig)
+		}
+	}
+
+	return c
+}
+
+// tlsMode returns the verification mode for the given vendor and facility.
+func (cfg *TLSConfig) tlsMode(vendor, facility string) TLSMode {
+	mode := cfg.Mode
+	matched := 0
+
+	for _, o := range cfg.Overrides {
+		if o.Vendor != "" && !strings.EqualFold(o.Vendor, vendor) {
+			continue
+		}
+
+		if o.Facility != "" && !strings.EqualFold(o.Facility, facility) {
+			continue
+		}
+
+		specificity := 0
+		if o.Vendor != "" {
+			specificity++
+		}
+
+		if o.Facility != "" {
+			specificity++
+		}
+
+		if specificity > matched {
+			mode = o.Mode
+			matched = specificity
+		}
+	}
+
+	if mode == "" {
+		return TLSModeInsecure
+	}
+
+	return mode
+}
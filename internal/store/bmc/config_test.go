@@ -0,0 +1,140 @@
+package bmc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacobweinstock/registrar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientConfig(t *testing.T) {
+	cfg := &Config{
+		Client: ClientConfig{
+			LoginTimeout: 2 * time.Minute,
+		},
+		Overrides: []ClientOverride{
+			{
+				Vendor:       "supermicro",
+				Model:        "x11dph-t",
+				ClientConfig: ClientConfig{PreferredProviders: []string{"supermicro"}},
+			},
+			{
+				Vendor: "supermicro",
+				ClientConfig: ClientConfig{
+					HTTPTimeout:        time.Minute,
+					PreferredProviders: []string{"gofish"},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		vendor   string
+		model    string
+		expected *ClientConfig
+	}{
+		{
+			"global parameters over defaults",
+			"dell",
+			"r6515",
+			&ClientConfig{
+				HTTPTimeout:    httpTimeout,
+				ConnectTimeout: connectTimeout,
+				LoginTimeout:   2 * time.Minute,
+				LogoutTimeout:  logoutTimeout,
+
+				RetryAttempts:    retryAttempts,
+				RetryInterval:    retryInterval,
+				MaxRetryInterval: maxRetryInterval,
+			},
+		},
+		{
+			"vendor override",
+			"Supermicro",
+			"x12",
+			&ClientConfig{
+				HTTPTimeout:        time.Minute,
+				ConnectTimeout:     connectTimeout,
+				LoginTimeout:       2 * time.Minute,
+				LogoutTimeout:      logoutTimeout,
+				PreferredProviders: []string{"gofish"},
+				RetryAttempts:      retryAttempts,
+				RetryInterval:      retryInterval,
+				MaxRetryInterval:   maxRetryInterval,
+			},
+		},
+		{
+			"vendor, model override applied over vendor override",
+			"supermicro",
+			"X11DPH-T",
+			&ClientConfig{
+				HTTPTimeout:        time.Minute,
+				ConnectTimeout:     connectTimeout,
+				LoginTimeout:       2 * time.Minute,
+				LogoutTimeout:      logoutTimeout,
+				PreferredProviders: []string{"supermicro"},
+				RetryAttempts:      retryAttempts,
+				RetryInterval:      retryInterval,
+				MaxRetryInterval:   maxRetryInterval,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, cfg.clientConfig(tc.vendor, tc.model))
+		})
+	}
+}
+
+func TestOrderProviders(t *testing.T) {
+	registry := &registrar.Registry{
+		Drivers: registrar.Drivers{
+			{Name: "ipmitool"},
+			{Name: "gofish"},
+			{Name: "dell"},
+			{Name: "supermicro"},
+		},
+	}
+
+	drivers := orderProviders(registry, []string{"dell", "gofish"}, []string{"IPMItool"})
+
+	names := []string{}
+	for _, d := range drivers {
+		names = append(names, d.Name)
+	}
+
+	assert.Equal(t, []string{"dell", "gofish", "supermicro"}, names)
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{"defaults", &Config{}, false},
+		{"verify mode", &Config{TLS: TLSConfig{Mode: TLSModeVerify}}, false},
+		{"nil config", nil, true},
+		{"invalid tls mode", &Config{TLS: TLSConfig{Mode: "strict"}}, true},
+		{"tls override without match", &Config{TLS: TLSConfig{Overrides: []TLSOverride{{Mode: TLSModeVerify}}}}, true},
+		{"missing ca bundle", &Config{TLS: TLSConfig{CABundle: "/nonexistent/ca.pem"}}, true},
+		{"negative client timeout", &Config{Client: ClientConfig{HTTPTimeout: -time.Second}}, true},
+		{"client override without vendor", &Config{Overrides: []ClientOverride{{Model: "r6515"}}}, true},
+		{"negative recovery timeout", &Config{Recovery: RecoveryConfig{Timeout: -time.Minute}}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrBMCConfig)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -2,25 +2,51 @@ package bmc
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
+	"sync"
 	"time"
 
+	"github.com/metal-toolbox/bmclib/constants"
+
 	"github.com/metal-toolbox/bioscfg/internal/model"
 )
 
 type server struct {
-	powerStatus        string
+	powerStatus        model.PowerState
+	restart            model.PowerAction
 	bootTime           time.Time
 	bootDevice         string
 	previousBootDevice string
 	persistent         bool
 	efiBoot            bool
+	biosJobs           []*Job
+	biosJobsExpire     map[string]time.Time
+	biosSettings       map[string]string
+	pendingSettings    map[string]string
+	secureBoot         SecureBoot
+	biosPassword       string
+	bootMode           BootMode
+	pendingBootMode    BootMode
 }
 
+// dryRunJobExpiry is the time a simulated BIOS job is left pending when the server is not rebooted to run it,
+// it is then failed and its staged settings discarded, as the BMC does with the expired scheduled jobs.
+const dryRunJobExpiry = time.Minute
+
 var (
 	errBmcCantFindServer = errors.New("dryrun BMC couldnt find server to set state")
 	errBmcServerOffline  = errors.New("dryrun BMC couldnt set boot device, server is off")
-	serverStates         = make(map[string]server)
+	errBmcBiosPassword   = errors.New("dryrun BMC current bios password mismatch")
+	errBmcPowerAction    = errors.New("dryrun BMC unknown power action")
+)
+
+var (
+	// serverStates are the simulated servers, shared by the clients of the concurrent conditions.
+	serverStates   = make(map[string]server)
+	serverStatesMu sync.Mutex
 )
 
 // DryRunBMC is an simulated implementation of the Queryor interface
@@ -30,6 +56,9 @@ type DryRunBMCClient struct {
 
 // NewDryRunBMCClient creates a new Queryor interface for a simulated BMC
 func NewDryRunBMCClient(asset *model.Asset) *DryRunBMCClient {
+	serverStatesMu.Lock()
+	defer serverStatesMu.Unlock()
+
 	_, ok := serverStates[asset.ID.String()]
 	if !ok {
 		serverStates[asset.ID.String()] = getDefaultSettings()
@@ -50,49 +79,59 @@ func (b *DryRunBMCClient) Close(_ context.Context) error {
 	return nil
 }
 
-// GetPowerState simulates returning the device power status
-func (b *DryRunBMCClient) GetPowerState(_ context.Context) (string, error) {
+// GetPowerState simulates returning the device power status, which is powering on while restarting
+func (b *DryRunBMCClient) GetPowerState(_ context.Context) (model.PowerState, error) {
 	server, err := b.getServer()
 	if err != nil {
-		return "", err
+		return model.PowerStateUnknown, err
 	}
 
 	return server.powerStatus, nil
 }
 
-// SetPowerState simulates setting the given power state on the device
-func (b *DryRunBMCClient) SetPowerState(_ context.Context, state string) error {
-	server, err := b.getServer()
-	if err != nil {
-		return err
-	}
+// SetPowerState simulates running the given power action on the device
+func (b *DryRunBMCClient) SetPowerState(_ context.Context, action model.PowerAction) error {
+	return b.updateServer(func(server *server) error {
+		return setPowerState(server, action)
+	})
+}
 
-	if isRestarting(state) {
-		server.bootTime = getRestartTime(state)
+func setPowerState(server *server, action model.PowerAction) error {
+	switch action {
+	case model.PowerActionOn:
+		if server.powerStatus == model.PowerStateOff {
+			server.restart = model.PowerActionCycle
+			server.bootTime = getRestartTime(server.restart)
+			server.powerStatus = model.PowerStatePoweringOn
+		}
+	case model.PowerActionOff, model.PowerActionSoftOff:
+		server.restart = ""
+		server.powerStatus = model.PowerStateOff
+	case model.PowerActionReset, model.PowerActionCycle:
+		server.restart = action
+		server.bootTime = getRestartTime(action)
+		server.powerStatus = model.PowerStatePoweringOn
+	default:
+		return errBmcPowerAction
 	}
 
-	server.powerStatus = state
-	serverStates[b.id] = *server
 	return nil
 }
 
 // SetBootDevice simulates setting the boot device of the remote device
 func (b *DryRunBMCClient) SetBootDevice(_ context.Context, device string, persistent, efiBoot bool) error {
-	server, err := b.getServer()
-	if err != nil {
-		return err
-	}
-
-	if server.powerStatus != "on" {
-		return errBmcServerOffline
-	}
+	return b.updateServer(func(server *server) error {
+		if server.powerStatus != model.PowerStateOn {
+			return errBmcServerOffline
+		}
 
-	server.previousBootDevice = server.bootDevice
-	server.bootDevice = device
-	server.persistent = persistent
-	server.efiBoot = efiBoot
+		server.previousBootDevice = server.bootDevice
+		server.bootDevice = device
+		server.persistent = persistent
+		server.efiBoot = efiBoot
 
-	return nil
+		return nil
+	})
 }
 
 // GetBootDevice simulates getting the boot device information of the remote device
@@ -102,7 +141,7 @@ func (b *DryRunBMCClient) GetBootDevice(_ context.Context) (device string, persi
 		return "", false, false, err
 	}
 
-	if server.powerStatus != "on" {
+	if server.powerStatus != model.PowerStateOn {
 		return "", false, false, errBmcServerOffline
 	}
 
@@ -115,59 +154,334 @@ func (b *DryRunBMCClient) PowerCycleBMC(_ context.Context) error {
 }
 
 // HostBooted reports whether or not the device has booted the host OS
-func (b *DryRunBMCClient) HostBooted(_ context.Context) (bool, error) {
-	return true, nil
+func (b *DryRunBMCClient) HostBooted(ctx context.Context) (bool, error) {
+	progress, err := b.BootProgress(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return progress.Booted(), nil
 }
 
-func (b *DryRunBMCClient) ResetBiosConfig(ctx context.Context) error {
-	_, ok := serverStates[b.id]
-	if !ok {
-		return errBmcCantFindServer
+// BootProgress simulates the POST state of a server, which is in UEFI POST while restarting
+func (b *DryRunBMCClient) BootProgress(_ context.Context) (*BootProgress, error) {
+	server, err := b.getServer()
+	if err != nil {
+		return nil, err
 	}
 
-	serverStates[b.id] = getDefaultSettings()
+	switch {
+	case server.restart.Restarts():
+		return &BootProgress{State: constants.POSTStateUEFI, Code: 0x92}, nil
+	case server.powerStatus == model.PowerStateOn:
+		return &BootProgress{State: constants.POSTStateOS, Code: 0x00}, nil
+	default:
+		return &BootProgress{State: constants.POSTCodeUnknown}, nil
+	}
+}
+
+func (b *DryRunBMCClient) ResetBiosConfig(_ context.Context) error {
+	return b.updateServer(func(state *server) error {
+		settings := getDefaultSettings()
+		settings.biosJobs = state.biosJobs
+		settings.biosJobsExpire = state.biosJobsExpire
+		settings.pendingSettings = state.pendingSettings
+		settings.biosPassword = state.biosPassword
+		*state = settings
+
+		queueBiosJob(state)
+
+		return setPowerState(state, model.PowerActionCycle)
+	})
+}
+
+// SetBiosConfigFromFile simulates a BIOS configuration job, which is run on the next reboot,
+// the settings are staged when given as a JSON object of attribute values
+func (b *DryRunBMCClient) SetBiosConfigFromFile(_ context.Context, cfg string) error {
+	return b.updateServer(func(server *server) error {
+		attrs := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(cfg), &attrs); err == nil {
+			if server.pendingSettings == nil {
+				server.pendingSettings = map[string]string{}
+			}
 
-	return b.SetPowerState(ctx, "cycle")
+			for name, value := range attrs {
+				server.pendingSettings[name] = fmt.Sprint(value)
+			}
+		}
+
+		queueBiosJob(server)
+
+		return nil
+	})
+}
+
+// BiosSettings returns the simulated current BIOS settings
+func (b *DryRunBMCClient) BiosSettings(_ context.Context) (map[string]string, error) {
+	server, err := b.getServer()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string, len(server.biosSettings))
+	for name, value := range server.biosSettings {
+		settings[name] = value
+	}
+
+	return settings, nil
+}
+
+// PendingBiosSettings returns the simulated staged BIOS settings
+func (b *DryRunBMCClient) PendingBiosSettings(_ context.Context) (map[string]string, error) {
+	server, err := b.getServer()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]string, len(server.pendingSettings))
+	for name, value := range server.pendingSettings {
+		if server.biosSettings[name] != value {
+			pending[name] = value
+		}
+	}
+
+	return pending, nil
+}
+
+// ClearPendingBiosSettings discards the simulated staged BIOS settings
+func (b *DryRunBMCClient) ClearPendingBiosSettings(_ context.Context) error {
+	return b.updateServer(func(server *server) error {
+		server.pendingSettings = nil
+		return nil
+	})
+}
+
+func queueBiosJob(server *server) {
+	job := &Job{
+		ID:    fmt.Sprintf("JID_DRYRUN_%d", len(server.biosJobs)+1),
+		Name:  "Configure: BIOS.Setup.1-1",
+		State: JobStatePending,
+	}
+
+	if server.biosJobsExpire == nil {
+		server.biosJobsExpire = map[string]time.Time{}
+	}
+
+	server.biosJobs = append(server.biosJobs, job)
+	server.biosJobsExpire[job.ID] = time.Now().Add(dryRunJobExpiry)
+}
+
+// GetSecureBoot returns the simulated UEFI Secure Boot state
+func (b *DryRunBMCClient) GetSecureBoot(_ context.Context) (*SecureBoot, error) {
+	server, err := b.getServer()
+	if err != nil {
+		return nil, err
+	}
+
+	state := server.secureBoot
+	return &state, nil
 }
 
-func (b *DryRunBMCClient) SetBiosConfigFromFile(_ context.Context, _ string) error {
+// SetSecureBoot simulates setting the UEFI Secure Boot state, applied on the next boot
+func (b *DryRunBMCClient) SetSecureBoot(_ context.Context, enable bool) error {
+	return b.updateServer(func(server *server) error {
+		server.secureBoot.Enabled = enable
+		return nil
+	})
+}
+
+// ResetSecureBootKeys simulates resetting the UEFI Secure Boot keys to their defaults
+func (b *DryRunBMCClient) ResetSecureBootKeys(_ context.Context) error {
+	return b.updateServer(func(server *server) error {
+		server.secureBoot.Mode = "UserMode"
+		return nil
+	})
+}
+
+// SetBiosPassword simulates changing the BIOS setup password
+func (b *DryRunBMCClient) SetBiosPassword(_ context.Context, current, password string) error {
+	return b.updateServer(func(server *server) error {
+		if server.biosPassword != current {
+			return errBmcBiosPassword
+		}
+
+		server.biosPassword = password
+		return nil
+	})
+}
+
+// GetBootMode returns the simulated firmware boot mode
+func (b *DryRunBMCClient) GetBootMode(_ context.Context) (BootMode, error) {
+	server, err := b.getServer()
+	if err != nil {
+		return BootModeUnknown, err
+	}
+
+	return server.bootMode, nil
+}
+
+// SetBootMode simulates staging the firmware boot mode, applied on the next boot
+func (b *DryRunBMCClient) SetBootMode(_ context.Context, mode BootMode) error {
+	return b.updateServer(func(server *server) error {
+		server.pendingBootMode = mode
+		return nil
+	})
+}
+
+// FirmwareVersions returns the simulated firmware versions
+func (b *DryRunBMCClient) FirmwareVersions(_ context.Context) (*FirmwareVersions, error) {
+	if _, err := b.getServer(); err != nil {
+		return nil, err
+	}
+
+	return &FirmwareVersions{BIOS: "1.0.0", BMC: "1.0.0"}, nil
+}
+
+// CheckReachable simulates a reachable BMC
+func (b *DryRunBMCClient) CheckReachable(_ context.Context) error {
 	return nil
 }
 
-// getServer gets a simulateed server state, and update power status and boot device if required
+// SupportedProviders simulates a single provider supporting all features
+func (b *DryRunBMCClient) SupportedProviders(_ ...Feature) []string {
+	return []string{"dryrun"}
+}
+
+// BiosJobs returns the simulated BIOS configuration jobs
+func (b *DryRunBMCClient) BiosJobs(_ context.Context) ([]*Job, error) {
+	server, err := b.getServer()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(server.biosJobs))
+	for _, job := range server.biosJobs {
+		j := *job
+		jobs = append(jobs, &j)
+	}
+
+	return jobs, nil
+}
+
+// getServer gets a copy of the simulated server state, and update power status and boot device if required
 func (b *DryRunBMCClient) getServer() (*server, error) {
-	state, ok := serverStates[b.id]
+	var state server
+
+	err := b.updateServer(func(server *server) error {
+		state = server.clone()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// updateServer runs the update on the simulated server state, once its power status and boot device are updated,
+// the state is stored when the update succeeds.
+func (b *DryRunBMCClient) updateServer(update func(*server) error) error {
+	serverStatesMu.Lock()
+	defer serverStatesMu.Unlock()
+
+	stored, ok := serverStates[b.id]
 	if !ok {
-		return nil, errBmcCantFindServer
+		return errBmcCantFindServer
 	}
 
-	if isRestarting(state.powerStatus) {
-		if time.Now().After(state.bootTime) {
-			state.powerStatus = "on"
+	state := stored.clone()
+	boot(&state)
+	expireBiosJobs(&state)
 
-			if !state.persistent {
-				state.bootDevice = state.previousBootDevice
-			}
+	if err := update(&state); err != nil {
+		return err
+	}
+
+	serverStates[b.id] = state
+
+	return nil
+}
+
+// boot completes the server restart once its boot time is reached, the staged changes are then applied.
+func boot(state *server) {
+	if !state.restart.Restarts() || !time.Now().After(state.bootTime) {
+		return
+	}
+
+	state.powerStatus = model.PowerStateOn
+	state.restart = ""
+
+	if !state.persistent {
+		state.bootDevice = state.previousBootDevice
+	}
+
+	state.secureBoot.CurrentBoot = state.secureBoot.Enabled
+
+	if state.pendingBootMode != "" {
+		state.bootMode = state.pendingBootMode
+		state.pendingBootMode = ""
+	}
+
+	// pending BIOS jobs and settings are applied on boot
+	for _, job := range state.biosJobs {
+		if job.State == JobStatePending {
+			job.State = JobStateCompleted
 		}
 	}
 
-	return &state, nil
+	if len(state.pendingSettings) > 0 {
+		if state.biosSettings == nil {
+			state.biosSettings = map[string]string{}
+		}
+
+		for name, value := range state.pendingSettings {
+			state.biosSettings[name] = value
+		}
+
+		state.pendingSettings = nil
+	}
 }
 
-func isRestarting(state string) bool {
-	switch state {
-	case "reset", "cycle":
-		return true
-	default:
-		return false
+// clone returns a copy of the server state, not sharing its jobs and settings.
+func (s *server) clone() server {
+	c := *s
+
+	c.biosJobs = make([]*Job, 0, len(s.biosJobs))
+	for _, job := range s.biosJobs {
+		j := *job
+		c.biosJobs = append(c.biosJobs, &j)
+	}
+
+	c.biosJobsExpire = maps.Clone(s.biosJobsExpire)
+	c.biosSettings = maps.Clone(s.biosSettings)
+	c.pendingSettings = maps.Clone(s.pendingSettings)
+
+	return c
+}
+
+// expireBiosJobs fails the pending BIOS jobs not run before their expiry, their staged settings are discarded.
+func expireBiosJobs(state *server) {
+	expired := false
+	now := time.Now()
+
+	for _, job := range state.biosJobs {
+		if job.State == JobStatePending && now.After(state.biosJobsExpire[job.ID]) {
+			job.State = JobStateFailed
+			job.Message = "job expired before the server was rebooted"
+			expired = true
+		}
+	}
+
+	if expired {
+		state.pendingSettings = nil
 	}
 }
 
-func getRestartTime(state string) time.Time {
-	switch state {
-	case "reset":
+func getRestartTime(action model.PowerAction) time.Time {
+	switch action {
+	case model.PowerActionReset:
 		return time.Now().Add(time.Second * 30) // Soft reboot should take longer than a hard reboot
-	case "cycle":
+	case model.PowerActionCycle:
 		return time.Now().Add(time.Second * 20)
 	default:
 		return time.Now() // No reboot necessary
@@ -177,12 +491,14 @@ func getRestartTime(state string) time.Time {
 func getDefaultSettings() server {
 	status := server{}
 
-	status.powerStatus = "on"
+	status.powerStatus = model.PowerStateOn
 	status.bootDevice = "disk"
 	status.previousBootDevice = "disk"
 	status.persistent = true
 	status.efiBoot = false
 	status.bootTime = time.Now()
+	status.secureBoot = SecureBoot{Mode: "UserMode"}
+	status.bootMode = BootModeUEFI
 
 	return status
 }
@@ -0,0 +1,77 @@
+package bmc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
+)
+
+func TestDryRunBiosJobExpiry(t *testing.T) {
+	ctx := context.Background()
+	client := NewDryRunBMCClient(&model.Asset{ID: uuid.New()})
+
+	require.NoError(t, client.SetBiosConfigFromFile(ctx, `{"BootMode": "Uefi"}`))
+
+	jobs, err := client.BiosJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, JobStatePending, jobs[0].State)
+
+	// the server is not rebooted to run the job before its expiry
+	serverStatesMu.Lock()
+	serverStates[client.id].biosJobsExpire[jobs[0].ID] = time.Now().Add(-time.Second)
+	serverStatesMu.Unlock()
+
+	jobs, err = client.BiosJobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, JobStateFailed, jobs[0].State)
+
+	pending, err := client.PendingBiosSettings(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestDryRunConcurrentClients(t *testing.T) {
+	ctx := context.Background()
+	asset := &model.Asset{ID: uuid.New()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			client := NewDryRunBMCClient(asset)
+			assert.NoError(t, client.SetBiosConfigFromFile(ctx, `{"BootMode": "Uefi"}`))
+
+			jobs, err := client.BiosJobs(ctx)
+			assert.NoError(t, err)
+
+			// the returned jobs are not shared with the simulated server
+			for _, job := range jobs {
+				job.State = JobStateFailed
+			}
+
+			_, err = client.PendingBiosSettings(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	jobs, err := NewDryRunBMCClient(asset).BiosJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 8)
+
+	for _, job := range jobs {
+		assert.Equal(t, JobStatePending, job.State)
+	}
+}
@@ -0,0 +1,155 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+
+	bmclibErrs "github.com/metal-toolbox/bmclib/errors"
+)
+
+// ErrorClass identifies the kind of failure returned by a BMC operation.
+type ErrorClass string
+
+const (
+	ErrorClassAuth           ErrorClass = "auth"
+	ErrorClassUnreachable    ErrorClass = "unreachable"
+	ErrorClassTimeout        ErrorClass = "timeout"
+	ErrorClassUnsupported    ErrorClass = "unsupported"
+	ErrorClassBusy           ErrorClass = "busy"
+	ErrorClassServerError    ErrorClass = "server_error"
+	ErrorClassInvalidPayload ErrorClass = "invalid_payload"
+	ErrorClassUnknown        ErrorClass = "unknown"
+)
+
+// Error is a classified BMC operation error.
+type Error struct {
+	Op    string
+	Class ErrorClass
+	err   error
+}
+
+func (e *Error) Error() string {
+	return "[" + string(e.Class) + "] " + e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Transient returns true when the operation may succeed if retried.
+func (c ErrorClass) Transient() bool {
+	switch c {
+	case ErrorClassUnreachable, ErrorClassTimeout, ErrorClassBusy, ErrorClassServerError:
+		return true
+	default:
+		return false
+	}
+}
+
+// ErrorClassOf returns the class of a BMC error, or an empty string if the error was not classified.
+func ErrorClassOf(err error) ErrorClass {
+	var bmcErr *Error
+	if errors.As(err, &bmcErr) {
+		return bmcErr.Class
+	}
+
+	return ""
+}
+
+// newError classifies the given error, errors already classified are returned as is.
+func newError(op string, err error) *Error {
+	var bmcErr *Error
+	if errors.As(err, &bmcErr) {
+		return bmcErr
+	}
+
+	return &Error{Op: op, Class: classify(err), err: err}
+}
+
+// error messages returned by the bmclib providers are matched to a class, in order.
+var errClassPatterns = []struct {
+	class   ErrorClass
+	pattern *regexp.Regexp
+}{
+	{
+		ErrorClassAuth,
+		regexp.MustCompile(`\b401\b|unauthorized|authentication|invalid credentials|failed to login|login failed`),
+	},
+	{
+		ErrorClassBusy,
+		regexp.MustCompile(`\b(503|409|429)\b|service unavailable|conflict|busy|job already|pending job|try again later`),
+	},
+	{
+		ErrorClassTimeout,
+		regexp.MustCompile(`timeout|deadline exceeded|timed out`),
+	},
+	{
+		ErrorClassServerError,
+		regexp.MustCompile(`\b(500|502|504)\b|internal server error|bad gateway`),
+	},
+	{
+		ErrorClassUnreachable,
+		regexp.MustCompile(`connection refused|no route to host|network is unreachable|connection reset|no such host|\beof\b`),
+	},
+	{
+		ErrorClassUnsupported,
+		regexp.MustCompile(`\b(404|405|501)\b|not implemented|not supported|unsupported|no compatible|method not allowed`),
+	},
+	{
+		ErrorClassInvalidPayload,
+		regexp.MustCompile(`\b(400|422)\b|bad request|invalid|malformed|unprocessable`),
+	},
+}
+
+// dial errors are returned before the request is sent to the BMC.
+var errDialPattern = regexp.MustCompile(`connection refused|no route to host|network is unreachable|no such host`)
+
+// notSent returns true when the operation failed establishing the connection, the BMC did not receive the request.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	return errDialPattern.MatchString(strings.ToLower(err.Error()))
+}
+
+func classify(err error) ErrorClass {
+	switch {
+	case errors.Is(err, bmclibErrs.ErrLoginFailed), errors.Is(err, bmclibErrs.ErrNotAuthenticated):
+		return ErrorClassAuth
+	case errors.Is(err, bmclibErrs.ErrNotImplemented),
+		errors.Is(err, bmclibErrs.ErrRedfishVersionIncompatible),
+		errors.Is(err, errBMCNotImplemented):
+		return ErrorClassUnsupported
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorClassUnreachable
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, c := range errClassPatterns {
+		if c.pattern.MatchString(msg) {
+			return c.class
+		}
+	}
+
+	return ErrorClassUnknown
+}
@@ -0,0 +1,77 @@
+package bmc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	bmclibErrs "github.com/metal-toolbox/bmclib/errors"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		{"bmclib login error", errors.Wrap(bmclibErrs.ErrLoginFailed, "provider: gofish"), ErrorClassAuth},
+		{"unauthorized response", errors.New("401: Unauthorized"), ErrorClassAuth},
+		{"context deadline", errors.Wrap(context.DeadlineExceeded, "provider: dell"), ErrorClassTimeout},
+		{"dial error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorClassUnreachable},
+		{"service unavailable", errors.New("503: Service Unavailable"), ErrorClassBusy},
+		{"internal server error", errors.New("500: Internal Server Error"), ErrorClassServerError},
+		{"dell job id is not a status code", errors.New("JID_123409 failed validation: bad request"), ErrorClassInvalidPayload},
+		{"not implemented", errBMCNotImplemented, ErrorClassUnsupported},
+		{"unknown", errors.New("something else"), ErrorClassUnknown},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, classify(tc.err))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	client := &Client{
+		asset:    &model.Asset{},
+		facility: "sandbox",
+		cfg:      &ClientConfig{RetryAttempts: 3},
+		logger:   logrus.NewEntry(logrus.New()),
+	}
+
+	cases := []struct {
+		name             string
+		idempotent       bool
+		err              error
+		expectedAttempts int
+	}{
+		{"transient error is retried", true, errors.New("503: Service Unavailable"), 3},
+		{"timeout is not retried when not idempotent", false, context.DeadlineExceeded, 1},
+		{"busy is retried when not idempotent", false, errors.New("409: Conflict"), 3},
+		{"dial error is retried when not idempotent", false, &net.OpError{Op: "dial", Err: errors.New("connection refused")}, 3},
+		{"connection reset is not retried when not idempotent", false, &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, 1},
+		{"eof is not retried when not idempotent", false, errors.New("Post \"https://bmc/redfish/v1\": EOF"), 1},
+		{"eof is retried when idempotent", true, errors.New("Post \"https://bmc/redfish/v1\": EOF"), 3},
+		{"auth error is not retried", true, bmclibErrs.ErrLoginFailed, 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			err := client.retry(context.Background(), "test", tc.idempotent, func(context.Context) error {
+				attempts++
+				return tc.err
+			})
+
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, classify(tc.err), ErrorClassOf(err))
+		})
+	}
+}
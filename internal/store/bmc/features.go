@@ -0,0 +1,20 @@
+package bmc
+
+import (
+	"github.com/jacobweinstock/registrar"
+	"github.com/metal-toolbox/bmclib/providers"
+)
+
+// Feature is a bmclib provider feature
+type Feature = registrar.Feature
+
+const (
+	FeaturePowerState            = providers.FeaturePowerState
+	FeaturePowerSet              = providers.FeaturePowerSet
+	FeatureBmcReset              = providers.FeatureBmcReset
+	FeaturePostCodeRead          = providers.FeaturePostCodeRead
+	FeatureGetBiosConfig         = providers.FeatureGetBiosConfiguration
+	FeatureSetBiosConfig         = providers.FeatureSetBiosConfiguration
+	FeatureSetBiosConfigFromFile = providers.FeatureSetBiosConfigurationFromFile
+	FeatureResetBiosConfig       = providers.FeatureResetBiosConfiguration
+)
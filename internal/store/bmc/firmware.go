@@ -0,0 +1,45 @@
+package bmc
+
+import (
+	"context"
+)
+
+// FirmwareVersions are the installed BIOS and BMC firmware versions.
+type FirmwareVersions struct {
+	BIOS string
+	BMC  string
+}
+
+// FirmwareVersions returns the BIOS version of the computer system, and the firmware version of its manager,
+// a version is empty when the BMC does not report it.
+func (b *Client) FirmwareVersions(ctx context.Context) (*FirmwareVersions, error) {
+	defer b.tracelog()
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := &FirmwareVersions{}
+	err = b.retry(ctx, "FirmwareVersions", true, func(context.Context) error {
+		system, err := computerSystem(rf)
+		if err != nil {
+			return err
+		}
+
+		versions.BIOS = system.BIOSVersion
+
+		managers, err := rf.Service.Managers()
+		if err != nil {
+			return err
+		}
+
+		if len(managers) > 0 {
+			versions.BMC = managers[0].FirmwareVersion
+		}
+
+		return nil
+	})
+
+	return versions, err
+}
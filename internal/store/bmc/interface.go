@@ -2,18 +2,34 @@ package bmc
 
 import (
 	"context"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
 )
 
 // Queryor interface abstracts calls to remote devices
 type BMC interface {
 	Open(ctx context.Context) error
 	Close(ctx context.Context) error
-	GetPowerState(ctx context.Context) (state string, err error)
-	SetPowerState(ctx context.Context, state string) error
+	GetPowerState(ctx context.Context) (state model.PowerState, err error)
+	SetPowerState(ctx context.Context, action model.PowerAction) error
 	SetBootDevice(ctx context.Context, device string, persistent, efiBoot bool) error
 	GetBootDevice(ctx context.Context) (device string, persistent, efiBoot bool, err error)
 	PowerCycleBMC(ctx context.Context) error
 	HostBooted(ctx context.Context) (bool, error)
+	BootProgress(ctx context.Context) (*BootProgress, error)
+	BiosSettings(ctx context.Context) (map[string]string, error)
 	ResetBiosConfig(ctx context.Context) error
 	SetBiosConfigFromFile(ctx context.Context, cfg string) error
+	CheckReachable(ctx context.Context) error
+	SupportedProviders(features ...Feature) []string
+	BiosJobs(ctx context.Context) ([]*Job, error)
+	PendingBiosSettings(ctx context.Context) (map[string]string, error)
+	ClearPendingBiosSettings(ctx context.Context) error
+	GetSecureBoot(ctx context.Context) (*SecureBoot, error)
+	SetSecureBoot(ctx context.Context, enable bool) error
+	ResetSecureBootKeys(ctx context.Context) error
+	SetBiosPassword(ctx context.Context, current, password string) error
+	GetBootMode(ctx context.Context) (BootMode, error)
+	SetBootMode(ctx context.Context, mode BootMode) error
+	FirmwareVersions(ctx context.Context) (*FirmwareVersions, error)
 }
@@ -0,0 +1,81 @@
+package bmc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metal-toolbox/bmclib/constants"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// JobState is the normalized state of a BMC job.
+type JobState string
+
+const (
+	JobStatePending   JobState = "pending"
+	JobStateRunning   JobState = "running"
+	JobStateCompleted JobState = "completed"
+	JobStateFailed    JobState = "failed"
+	JobStateUnknown   JobState = "unknown"
+)
+
+// Job is a BIOS configuration job, or task queued on the BMC.
+type Job struct {
+	ID      string
+	Name    string
+	State   JobState
+	Message string
+}
+
+// Finished returns true when the job reached a final state.
+func (j *Job) Finished() bool {
+	return j.State == JobStateCompleted || j.State == JobStateFailed
+}
+
+// BootProgress is the host POST state and code reported by the BMC,
+// the State is one of the bmclib constants.POSTState values, or constants.POSTCodeUnknown.
+type BootProgress struct {
+	State string
+	Code  int
+}
+
+// Booted returns true when the host completed POST and is booting the OS.
+func (p *BootProgress) Booted() bool {
+	return p.State == constants.POSTStateOS
+}
+
+func (p *BootProgress) String() string {
+	return fmt.Sprintf("%s (post code 0x%02x)", p.State, p.Code)
+}
+
+func jobStateFromTask(state redfish.TaskState) JobState {
+	switch state {
+	case redfish.NewTaskState, redfish.PendingTaskState, redfish.StartingTaskState, redfish.SuspendedTaskState:
+		return JobStatePending
+	case redfish.RunningTaskState, redfish.StoppingTaskState, redfish.CancellingTaskState, redfish.ServiceTaskState:
+		return JobStateRunning
+	case redfish.CompletedTaskState:
+		return JobStateCompleted
+	case redfish.KilledTaskState, redfish.ExceptionTaskState, redfish.CancelledTaskState, redfish.InterruptedTaskState:
+		return JobStateFailed
+	default:
+		return JobStateUnknown
+	}
+}
+
+// jobStateFromDell returns the JobState for the Dell iDRAC JobState values.
+func jobStateFromDell(state string) JobState {
+	switch strings.ToLower(state) {
+	case "new", "scheduled", "scheduling", "downloading", "downloaded", "readyforexecution",
+		"waiting", "paused", "rebootpending", "pendingactivation":
+		return JobStatePending
+	case "running", "rebootcompleted":
+		return JobStateRunning
+	case "completed":
+		return JobStateCompleted
+	case "failed", "completedwitherrors", "rebootfailed":
+		return JobStateFailed
+	default:
+		return JobStateUnknown
+	}
+}
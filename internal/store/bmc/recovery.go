@@ -0,0 +1,108 @@
+package bmc
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+)
+
+var (
+	errBMCRecovery = errors.New("bmc recovery failed")
+)
+
+// ResetLimiter caps the number of BMC resets per asset.
+type ResetLimiter interface {
+	// Allow records a BMC reset of the asset for the current day,
+	// false is returned when the asset reached the maximum resets for the day.
+	Allow(ctx context.Context, assetID string, maxPerDay int) (bool, error)
+}
+
+// recoverable returns true when the operation failed because the BMC appears wedged,
+// and the operation can be resumed after a BMC reset.
+func (b *Client) recoverable(err error, idempotent bool) bool {
+	if b.recovery == nil || !b.recovery.Enabled || b.resets == nil || b.recovering || !idempotent {
+		return false
+	}
+
+	switch ErrorClassOf(err) {
+	case ErrorClassTimeout, ErrorClassServerError:
+		return true
+	default:
+		return false
+	}
+}
+
+// recoverBMC resets the BMC, waits for it to return and re-opens the BMC session.
+func (b *Client) recoverBMC(ctx context.Context, op string) error {
+	b.recovering = true
+	defer func() { b.recovering = false }()
+
+	allowed, err := b.resets.Allow(ctx, b.asset.ID.String(), b.recovery.MaxResetsPerDay)
+	if err != nil {
+		metrics.BMCRecovery(b.asset.Vendor, "failed", b.facility)
+		return errors.Wrap(errBMCRecovery, "error counting bmc resets: "+err.Error())
+	}
+
+	if !allowed {
+		metrics.BMCRecovery(b.asset.Vendor, "capped", b.facility)
+		return errors.Wrap(errBMCRecovery, "bmc reset limit reached for today")
+	}
+
+	b.logger.WithField("operation", op).Warn("bmc unresponsive, resetting bmc")
+
+	if _, err := b.client.ResetBMC(ctx, "GracefulRestart"); err != nil {
+		metrics.BMCRecovery(b.asset.Vendor, "failed", b.facility)
+		return errors.Wrap(errBMCRecovery, "bmc reset: "+err.Error())
+	}
+
+	// the sessions do not survive the reset
+	b.closeRedfish()
+	if err := b.client.Close(ctx); err != nil {
+		b.logger.WithError(err).Debug("bmc session close error after reset")
+	}
+
+	if err := b.waitBMC(ctx); err != nil {
+		metrics.BMCRecovery(b.asset.Vendor, "failed", b.facility)
+		return err
+	}
+
+	metrics.BMCRecovery(b.asset.Vendor, "recovered", b.facility)
+	b.logger.WithField("operation", op).Info("bmc recovered after reset, resuming operation")
+
+	return nil
+}
+
+// waitBMC waits for the BMC to accept a new session after a reset, with an exponential backoff.
+func (b *Client) waitBMC(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, b.recovery.Timeout)
+	defer cancel()
+
+	// the BMC may still accept connections while it goes down for the reset
+	wait := b.cfg.MaxRetryInterval
+	interval := b.cfg.RetryInterval
+
+	for {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return errors.Wrap(errBMCRecovery, "bmc did not return after reset within "+b.recovery.Timeout.String())
+		}
+
+		wait = interval
+		interval = min(interval*2, b.cfg.MaxRetryInterval)
+
+		if err := b.CheckReachable(ctx); err != nil {
+			continue
+		}
+
+		if err := b.client.Open(ctx); err != nil {
+			b.logger.WithError(err).Debug("bmc session open error after reset")
+			continue
+		}
+
+		return nil
+	}
+}
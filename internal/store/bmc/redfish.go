@@ -0,0 +1,182 @@
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/metal-toolbox/bmclib/constants"
+	"github.com/pkg/errors"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const (
+	// Dell iDRAC job queue, this lists the BIOS configuration jobs which are not all listed in the TaskService.
+	dellJobsURI = "/redfish/v1/Managers/iDRAC.Embedded.1/Jobs?$expand=*($levels=1)"
+
+	dellJobTypeBIOS = "BIOSConfiguration"
+)
+
+// redfishClient returns a Redfish session to the BMC for the operations not exposed through bmclib,
+// the session is opened on first use and closed along with the bmclib session.
+// A BMC without a Redfish service fails with the ErrorClassUnsupported class.
+// The session is opened before the retried operation, not within it, the open is retried on its own.
+func (b *Client) redfishClient(ctx context.Context) (*gofish.APIClient, error) {
+	if b.rf != nil {
+		return b.rf, nil
+	}
+
+	httpClient := *b.httpClient
+	httpClient.Transport = b.httpClient.Transport.(*http.Transport).Clone()
+
+	var rf *gofish.APIClient
+	err := b.retry(ctx, "RedfishOpen", true, func(ctx context.Context) error {
+		var err error
+		rf, err = gofish.ConnectContext(ctx, gofish.ClientConfig{
+			Endpoint:   "https://" + b.asset.BmcAddress.URLHost() + ":" + b.redfishPort(),
+			Username:   b.asset.BmcUsername,
+			Password:   b.asset.BmcPassword,
+			HTTPClient: &httpClient,
+		})
+		if noRedfishService(err) {
+			return errors.Wrap(errBMCNotImplemented, "no redfish service: "+err.Error())
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, newError("RedfishOpen", err)
+	}
+
+	b.rf = rf
+
+	return b.rf, nil
+}
+
+// noRedfishService returns true when the Redfish service root is not found, or not a Redfish resource.
+func noRedfishService(err error) bool {
+	var rfErr *common.Error
+	if errors.As(err, &rfErr) {
+		return rfErr.HTTPReturnedStatusCode == http.StatusNotFound
+	}
+
+	var syntaxErr *json.SyntaxError
+
+	return errors.As(err, &syntaxErr)
+}
+
+func (b *Client) redfishPort() string {
+	if b.asset.BmcAddress.Port != "" {
+		return b.asset.BmcAddress.Port
+	}
+
+	return httpsPort
+}
+
+func (b *Client) closeRedfish() {
+	if b.rf == nil {
+		return
+	}
+
+	b.rf.Logout()
+	b.rf = nil
+}
+
+// BiosJobs returns the BIOS configuration jobs queued on the BMC.
+func (b *Client) BiosJobs(ctx context.Context) ([]*Job, error) {
+	defer b.tracelog()
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*Job
+	err = b.retry(ctx, "BiosJobs", true, func(context.Context) error {
+		var err error
+		if strings.EqualFold(b.asset.Vendor, constants.Dell) {
+			jobs, err = dellBiosJobs(rf)
+		} else {
+			jobs, err = redfishBiosTasks(rf)
+		}
+
+		return err
+	})
+
+	return jobs, err
+}
+
+// dellJob is a member of the Dell iDRAC job queue.
+type dellJob struct {
+	ID       string `json:"Id"`
+	Name     string `json:"Name"`
+	JobType  string `json:"JobType"`
+	JobState string `json:"JobState"`
+	Message  string `json:"Message"`
+}
+
+func dellBiosJobs(rf *gofish.APIClient) ([]*Job, error) {
+	collection := struct {
+		Members []dellJob `json:"Members"`
+	}{}
+
+	if err := getJSON(rf, dellJobsURI, &collection); err != nil {
+		return nil, err
+	}
+
+	jobs := []*Job{}
+	for _, j := range collection.Members {
+		if j.JobType != dellJobTypeBIOS {
+			continue
+		}
+
+		jobs = append(jobs, &Job{
+			ID:      j.ID,
+			Name:    j.Name,
+			State:   jobStateFromDell(j.JobState),
+			Message: j.Message,
+		})
+	}
+
+	return jobs, nil
+}
+
+func redfishBiosTasks(rf *gofish.APIClient) ([]*Job, error) {
+	tasks, err := rf.Service.Tasks()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []*Job{}
+	for _, t := range tasks {
+		if !isBiosTask(t) {
+			continue
+		}
+
+		jobs = append(jobs, jobFromTask(t))
+	}
+
+	return jobs, nil
+}
+
+func isBiosTask(t *redfish.Task) bool {
+	return strings.Contains(strings.ToLower(t.Name), "bios") ||
+		strings.Contains(strings.ToLower(t.Payload.TargetURI), "/bios")
+}
+
+func jobFromTask(t *redfish.Task) *Job {
+	job := &Job{
+		ID:    t.ID,
+		Name:  t.Name,
+		State: jobStateFromTask(t.TaskState),
+	}
+
+	if len(t.Messages) > 0 {
+		job.Message = t.Messages[len(t.Messages)-1].Message
+	}
+
+	return job
+}
@@ -0,0 +1,175 @@
+package bmc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
+)
+
+func TestRedfishClientUnsupported(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			"service root not found",
+			func(w http.ResponseWriter, _ *http.Request) { http.NotFound(w, nil) },
+		},
+		{
+			"service root not redfish",
+			func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("<html>web ui</html>")) },
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(tc.handler)
+			defer srv.Close()
+
+			addr, err := model.ParseBMCAddress(strings.TrimPrefix(srv.URL, "https://"))
+			require.NoError(t, err)
+
+			client := &Client{
+				httpClient: srv.Client(),
+				asset:      &model.Asset{BmcAddress: addr},
+				facility:   "sandbox",
+				cfg:        &ClientConfig{RetryAttempts: 1},
+				logger:     logrus.NewEntry(logrus.New()),
+			}
+
+			_, err = client.redfishClient(context.Background())
+			assert.Equal(t, ErrorClassUnsupported, ErrorClassOf(err), err)
+		})
+	}
+}
+
+func TestDeleteBiosSettings(t *testing.T) {
+	const settingsURI = "/redfish/v1/Systems/1/Bios/Settings"
+
+	cases := []struct {
+		name          string
+		allow         string
+		deleteStatus  int
+		expectDeleted bool
+		expectClass   ErrorClass
+	}{
+		{"delete allowed", "GET, PATCH, DELETE", http.StatusNoContent, true, ""},
+		{"allow not advertised", "", http.StatusNoContent, true, ""},
+		{"delete not allowed", "GET, PATCH", http.StatusNoContent, false, ErrorClassUnsupported},
+		{"delete rejected", "", http.StatusMethodNotAllowed, true, ErrorClassUnsupported},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			deleted := false
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == settingsURI && r.Method == http.MethodDelete:
+					deleted = true
+					w.WriteHeader(tc.deleteStatus)
+				case r.URL.Path == settingsURI:
+					if tc.allow != "" {
+						w.Header().Set("Allow", tc.allow)
+					}
+
+					_, _ = w.Write([]byte(`{"Attributes": {}}`))
+				default:
+					_, _ = w.Write([]byte(`{"@odata.id": "/redfish/v1/"}`))
+				}
+			}))
+			defer srv.Close()
+
+			addr, err := model.ParseBMCAddress(strings.TrimPrefix(srv.URL, "https://"))
+			require.NoError(t, err)
+
+			client := &Client{
+				httpClient: srv.Client(),
+				asset:      &model.Asset{BmcAddress: addr},
+				facility:   "sandbox",
+				cfg:        &ClientConfig{RetryAttempts: 1},
+				logger:     logrus.NewEntry(logrus.New()),
+			}
+
+			rf, err := client.redfishClient(context.Background())
+			require.NoError(t, err)
+
+			err = deleteBiosSettings(rf, settingsURI)
+			assert.Equal(t, tc.expectDeleted, deleted)
+
+			if tc.expectClass == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Equal(t, tc.expectClass, classify(err), err)
+		})
+	}
+}
+
+func TestRedfishRetryAttempts(t *testing.T) {
+	cases := []struct {
+		name          string
+		failRoot      bool
+		expectRoot    int32
+		expectSystems int32
+	}{
+		{"session open failing", true, 3, 0},
+		{"operation failing", false, 1, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var root, systems atomic.Int32
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch strings.TrimSuffix(r.URL.Path, "/") {
+				case "/redfish/v1":
+					root.Add(1)
+					if tc.failRoot {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+
+					_, _ = w.Write([]byte(`{"@odata.id": "/redfish/v1/", "Systems": {"@odata.id": "/redfish/v1/Systems"}}`))
+				case "/redfish/v1/Systems":
+					systems.Add(1)
+					w.WriteHeader(http.StatusServiceUnavailable)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			addr, err := model.ParseBMCAddress(strings.TrimPrefix(srv.URL, "https://"))
+			require.NoError(t, err)
+
+			asset := &model.Asset{BmcAddress: addr}
+			cfg := &ClientConfig{RetryAttempts: 3, RetryInterval: time.Millisecond, MaxRetryInterval: time.Millisecond}
+			logger := logrus.NewEntry(logrus.New())
+
+			client := &Client{
+				client:     newBmclibClient(asset, cfg, srv.Client(), logger),
+				httpClient: srv.Client(),
+				asset:      asset,
+				facility:   "sandbox",
+				cfg:        cfg,
+				logger:     logger,
+			}
+
+			// the attempts of the session open and the operation are not multiplied
+			_, err = client.GetSecureBoot(context.Background())
+			assert.Equal(t, ErrorClassBusy, ErrorClassOf(err), err)
+			assert.Equal(t, tc.expectRoot, root.Load())
+			assert.Equal(t, tc.expectSystems, systems.Load())
+		})
+	}
+}
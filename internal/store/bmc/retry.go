@@ -0,0 +1,80 @@
+package bmc
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+)
+
+// default retry parameters, see ClientConfig
+const (
+	retryAttempts    = 4
+	retryInterval    = 10 * time.Second
+	maxRetryInterval = 2 * time.Minute
+)
+
+// retry runs the BMC operation, retrying with an exponential backoff when it fails with a transient error.
+//
+// Operations that are not idempotent are only retried when the BMC could not have acted on the request,
+// that is when the connection could not be established or the BMC reported it was busy,
+// since a request timed out or interrupted once sent may have been applied.
+//
+// When BMC recovery is enabled, and the retries of an idempotent operation are exhausted with the BMC
+// timing out or returning server errors, the BMC is reset and the operation is resumed.
+func (b *Client) retry(ctx context.Context, op string, idempotent bool, fn func(context.Context) error) error {
+	err := b.attempt(ctx, op, idempotent, fn)
+	if err == nil || !b.recoverable(err, idempotent) {
+		return err
+	}
+
+	if errRecover := b.recoverBMC(ctx, op); errRecover != nil {
+		b.logger.WithError(errRecover).WithField("operation", op).Warn("bmc recovery failed")
+		return err
+	}
+
+	return b.attempt(ctx, op, idempotent, fn)
+}
+
+func (b *Client) attempt(ctx context.Context, op string, idempotent bool, fn func(context.Context) error) error {
+	interval := b.cfg.RetryInterval
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		bmcErr := newError(op, err)
+		metrics.BMCError(op, string(bmcErr.Class), b.facility)
+
+		if attempt >= b.cfg.RetryAttempts || !retryable(bmcErr, idempotent) {
+			return bmcErr
+		}
+
+		b.logger.WithFields(logrus.Fields{
+			"operation": op,
+			"class":     bmcErr.Class,
+			"attempt":   attempt,
+			"err":       err.Error(),
+		}).Warn("bmc operation failed, retrying")
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return bmcErr
+		}
+
+		interval = min(interval*2, b.cfg.MaxRetryInterval)
+	}
+}
+
+func retryable(err *Error, idempotent bool) bool {
+	if idempotent {
+		return err.Class.Transient()
+	}
+
+	return err.Class == ErrorClassBusy || (err.Class == ErrorClassUnreachable && notSent(err))
+}
@@ -0,0 +1,106 @@
+package bmc
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// SecureBoot is the UEFI Secure Boot state of the server.
+type SecureBoot struct {
+	// Enabled is the configured state, applied on the next boot.
+	Enabled bool
+	// CurrentBoot is true when Secure Boot was enforced in the current boot.
+	CurrentBoot bool
+	// Mode is the UEFI Secure Boot mode - SetupMode, UserMode, AuditMode or DeployedMode.
+	Mode string
+}
+
+// GetSecureBoot returns the UEFI Secure Boot state of the server.
+func (b *Client) GetSecureBoot(ctx context.Context) (*SecureBoot, error) {
+	defer b.tracelog()
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var state *SecureBoot
+	err = b.retry(ctx, "GetSecureBoot", true, func(context.Context) error {
+		sb, err := systemSecureBoot(rf)
+		if err != nil {
+			return err
+		}
+
+		state = &SecureBoot{
+			Enabled:     sb.SecureBootEnable,
+			CurrentBoot: sb.SecureBootCurrentBoot == redfish.EnabledSecureBootCurrentBootType,
+			Mode:        string(sb.SecureBootMode),
+		}
+
+		return nil
+	})
+
+	return state, err
+}
+
+// SetSecureBoot enables or disables UEFI Secure Boot, the change is applied on the next boot.
+func (b *Client) SetSecureBoot(ctx context.Context, enable bool) error {
+	defer b.tracelog()
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return b.retry(ctx, "SetSecureBoot", true, func(context.Context) error {
+		sb, err := systemSecureBoot(rf)
+		if err != nil {
+			return err
+		}
+
+		sb.SecureBootEnable = enable
+
+		return sb.Update()
+	})
+}
+
+// ResetSecureBootKeys resets the UEFI Secure Boot key databases to their default values.
+func (b *Client) ResetSecureBootKeys(ctx context.Context) error {
+	defer b.tracelog()
+
+	rf, err := b.redfishClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return b.retry(ctx, "ResetSecureBootKeys", true, func(context.Context) error {
+		sb, err := systemSecureBoot(rf)
+		if err != nil {
+			return err
+		}
+
+		return sb.ResetKeys(redfish.ResetAllKeysToDefaultResetKeysType)
+	})
+}
+
+// systemSecureBoot returns the SecureBoot resource of the computer system, with the session opened before, as systemBios.
+func systemSecureBoot(rf *gofish.APIClient) (*redfish.SecureBoot, error) {
+	system, err := computerSystem(rf)
+	if err != nil {
+		return nil, err
+	}
+
+	sb, err := system.SecureBoot()
+	if err != nil {
+		return nil, err
+	}
+
+	if sb == nil {
+		return nil, errors.Wrap(errBMCNotImplemented, "no SecureBoot resource")
+	}
+
+	return sb, nil
+}
@@ -0,0 +1,115 @@
+package bmc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+	"github.com/metal-toolbox/bioscfg/internal/model"
+)
+
+var (
+	errCertPinMismatch = errors.New("bmc certificate does not match pinned fingerprint")
+)
+
+// newTLSConfig returns the TLS client configuration for the given asset BMC, in the given facility.
+//
+// When a certificate fingerprint is recorded for the asset, the BMC leaf certificate is required to match it,
+// in which case the connection is considered verified even when the chain is not.
+func newTLSConfig(cfg *TLSConfig, asset *model.Asset, facility string) (*tls.Config, error) {
+	pin, err := parseFingerprint(asset.BmcCertFingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.tlsMode(asset.Vendor, facility) == TLSModeVerify {
+		roots, err := rootCAs(cfg.CABundle)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig := &tls.Config{
+			RootCAs:    roots,
+			MinVersion: tls.VersionTLS12,
+		}
+
+		if pin != nil {
+			tlsConfig.VerifyConnection = verifyPin(pin)
+		}
+
+		return tlsConfig, nil
+	}
+
+	// nolint:gosec // verification is either done by the pinned fingerprint or counted as unverified.
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	if pin != nil {
+		tlsConfig.VerifyConnection = verifyPin(pin)
+	} else {
+		tlsConfig.VerifyConnection = func(tls.ConnectionState) error {
+			metrics.BMCUnverifiedConnection(asset.Vendor, facility)
+			return nil
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// rootCAs returns the system cert pool with the CA bundle appended.
+func rootCAs(bundle string) (*x509.CertPool, error) {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+
+	if bundle == "" {
+		return roots, nil
+	}
+
+	pem, err := os.ReadFile(bundle)
+	if err != nil {
+		return nil, errors.Wrap(ErrBMCConfig, "tls ca bundle: "+err.Error())
+	}
+
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, errors.Wrap(ErrBMCConfig, "tls ca bundle contains no valid certificates: "+bundle)
+	}
+
+	return roots, nil
+}
+
+func verifyPin(pin []byte) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.Wrap(errCertPinMismatch, "no peer certificate presented")
+		}
+
+		sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+		if !bytes.Equal(sum[:], pin) {
+			return errors.Wrap(errCertPinMismatch, "got "+hex.EncodeToString(sum[:]))
+		}
+
+		return nil
+	}
+}
+
+// parseFingerprint parses a hex encoded SHA-256 certificate fingerprint,
+// with or without colon separators.
+func parseFingerprint(fingerprint string) ([]byte, error) {
+	if fingerprint == "" {
+		return nil, nil
+	}
+
+	pin, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+	if err != nil || len(pin) != sha256.Size {
+		return nil, errors.Wrap(ErrBMCConfig, "invalid sha256 certificate fingerprint: "+fingerprint)
+	}
+
+	return pin, nil
+}
@@ -0,0 +1,92 @@
+package bmc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metal-toolbox/bioscfg/internal/model"
+)
+
+func TestTLSMode(t *testing.T) {
+	cfg := &TLSConfig{
+		Overrides: []TLSOverride{
+			{Vendor: "dell", Mode: TLSModeVerify},
+			{Facility: "fc1", Mode: TLSModeVerify},
+			{Vendor: "dell", Facility: "fc2", Mode: TLSModeInsecure},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		vendor   string
+		facility string
+		expected TLSMode
+	}{
+		{"default is insecure", "supermicro", "fc3", TLSModeInsecure},
+		{"vendor override", "Dell", "fc3", TLSModeVerify},
+		{"facility override", "supermicro", "fc1", TLSModeVerify},
+		{"vendor and facility override wins", "dell", "fc2", TLSModeInsecure},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, cfg.tlsMode(tc.vendor, tc.facility))
+		})
+	}
+}
+
+func TestNewTLSConfigPin(t *testing.T) {
+	cert := &x509.Certificate{Raw: []byte("bmc certificate")}
+	sum := sha256.Sum256(cert.Raw)
+
+	cases := []struct {
+		name        string
+		fingerprint string
+		peer        *x509.Certificate
+		expectedErr string
+	}{
+		{
+			"invalid fingerprint",
+			"foo",
+			cert,
+			"invalid sha256 certificate fingerprint",
+		},
+		{
+			"matching fingerprint",
+			hex.EncodeToString(sum[:]),
+			cert,
+			"",
+		},
+		{
+			"mismatched fingerprint",
+			hex.EncodeToString(make([]byte, sha256.Size)),
+			cert,
+			errCertPinMismatch.Error(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			asset := &model.Asset{BmcCertFingerprint: tc.fingerprint}
+
+			tlsConfig, err := newTLSConfig(&TLSConfig{}, asset, "sandbox")
+			if err != nil {
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+
+			err = tlsConfig.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{tc.peer}})
+			if tc.expectedErr != "" {
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+
+			assert.Nil(t, err)
+		})
+	}
+}
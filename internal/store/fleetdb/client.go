@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
@@ -21,18 +22,20 @@ var (
 	timeout = 30 * time.Second
 )
 
-// NewFleetDBClient instantiates and returns a serverService client
-func NewFleetDBClient(ctx context.Context, cfg *Config, logger *logrus.Logger) (*fleetdbapi.Client, error) {
+// NewFleetDBClient instantiates and returns a serverService client,
+// the OAuth token source of the client is returned when authentication is enabled.
+func NewFleetDBClient(ctx context.Context, cfg *Config, logger *logrus.Logger) (*fleetdbapi.Client, oauth2.TokenSource, error) {
 	err := cfg.validate()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if cfg.Authenticate {
 		return newFleetDBClientWithOAuthOtel(ctx, cfg, logger)
 	}
 
-	return newFleetDBClientWithOtel(cfg, logger)
+	client, err := newFleetDBClientWithOtel(cfg, logger)
+	return client, nil, err
 }
 
 // returns a fleetdb retryable client with Otel
@@ -70,7 +73,7 @@ func newFleetDBClientWithOtel(cfg *Config, logger *logrus.Logger) (*fleetdbapi.C
 }
 
 // returns a fleetdb retryable http client with Otel and Oauth wrapped in
-func newFleetDBClientWithOAuthOtel(ctx context.Context, cfg *Config, logger *logrus.Logger) (*fleetdbapi.Client, error) {
+func newFleetDBClientWithOAuthOtel(ctx context.Context, cfg *Config, logger *logrus.Logger) (*fleetdbapi.Client, oauth2.TokenSource, error) {
 	logger.Info("fleetdb client ctor")
 
 	// init retryable http client
@@ -82,7 +85,7 @@ func newFleetDBClientWithOAuthOtel(ctx context.Context, cfg *Config, logger *log
 	// setup oidc provider
 	provider, err := oidc.NewProvider(ctx, cfg.OidcIssuerURL)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// clientID defaults to 'bioscfg'
@@ -101,8 +104,11 @@ func newFleetDBClientWithOAuthOtel(ctx context.Context, cfg *Config, logger *log
 		EndpointParams: url.Values{"audience": []string{cfg.OidcAudienceURL}},
 	}
 
+	// the token source is shared with the health checks, to report the token validity
+	tokens := oauthConfig.TokenSource(ctx)
+
 	// wrap OAuth transport, cookie jar in the retryable client
-	oAuthclient := oauthConfig.Client(ctx)
+	oAuthclient := oauth2.NewClient(ctx, tokens)
 
 	retryableClient.HTTPClient.Transport = oAuthclient.Transport
 	retryableClient.HTTPClient.Jar = oAuthclient.Jar
@@ -111,9 +117,14 @@ func newFleetDBClientWithOAuthOtel(ctx context.Context, cfg *Config, logger *log
 	client := retryableClient.StandardClient()
 	client.Timeout = timeout
 
-	return fleetdbapi.NewClientWithToken(
+	apiclient, err := fleetdbapi.NewClientWithToken(
 		cfg.OidcClientSecret,
 		cfg.URL,
 		client,
 	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return apiclient, tokens, nil
 }
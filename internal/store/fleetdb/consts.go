@@ -3,9 +3,21 @@ package fleetdb
 // TODO: move these consts into the hollow-toolbox to share between controllers.
 
 const (
-	// fleetdb BMC address attribute key
+	// ServerCredentialTypeBIOS is the fleetdb credential type of the BIOS setup password to set on the server.
+	ServerCredentialTypeBIOS = "bios"
+
+	// ServerCredentialTypeBIOSCurrent is the fleetdb credential type of the BIOS setup password set on the server,
+	// it is updated by bioscfg once the password is changed.
+	ServerCredentialTypeBIOSCurrent = "bios_current"
+)
+
+const (
+	// fleetdb BMC address attribute key, the address is an IP address or hostname with an optional port.
 	bmcIPAddressAttributeKey = "address"
 
+	// fleetdb BMC certificate SHA-256 fingerprint attribute key
+	bmcCertFingerprintAttributeKey = "cert_fingerprint"
+
 	fleetdbBMCInfoNS = "sh.hollow.bmc_info"
 
 	// fleetdb namespace prefix the data is stored in.
@@ -22,4 +34,10 @@ const (
 
 	// server service server vendor attribute key
 	serverVendorAttributeKey = "vendor"
+
+	// the BIOS password rotation time is stored in this namespace.
+	biosPasswordAttributeNS = fleetdbNSPrefix + ".bios_password"
+
+	// BIOS password rotation time attribute key
+	biosPasswordRotatedAtAttributeKey = "rotated_at"
 )
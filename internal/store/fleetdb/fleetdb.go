@@ -3,7 +3,8 @@ package fleetdb
 import (
 	"context"
 	"encoding/json"
-	"net"
+	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/metal-toolbox/bioscfg/internal/model"
@@ -11,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/oauth2"
 
 	fleetdbapi "github.com/metal-toolbox/fleetdb/pkg/api/v1"
 )
@@ -24,11 +26,13 @@ type Store struct {
 	api    *fleetdbapi.Client
 	logger *logrus.Logger
 	config *Config
+	// tokens is set when the client authenticates
+	tokens oauth2.TokenSource
 }
 
 // New returns a fleetdb store queryor to lookup and publish assets to, from the store.
 func New(ctx context.Context, cfg *Config, logger *logrus.Logger) (*Store, error) {
-	apiclient, err := NewFleetDBClient(ctx, cfg, logger)
+	apiclient, tokens, err := NewFleetDBClient(ctx, cfg, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +41,7 @@ func New(ctx context.Context, cfg *Config, logger *logrus.Logger) (*Store, error
 		api:    apiclient,
 		logger: logger,
 		config: cfg,
+		tokens: tokens,
 	}
 
 	return s, nil
@@ -68,6 +73,105 @@ func (s *Store) AssetByID(ctx context.Context, id uuid.UUID) (*model.Asset, erro
 	return toAsset(server, credential)
 }
 
+// RefreshBMCCredential queries fleetdb for the BMC credential of the asset, and updates the asset with it,
+// the credential is not cached, so a credential rotated after the asset was looked up is returned.
+// False is returned when the credential is unchanged.
+func (s *Store) RefreshBMCCredential(ctx context.Context, asset *model.Asset) (bool, error) {
+	ctx, span := otel.Tracer(pkgName).Start(ctx, "fleetdb.RefreshBMCCredential")
+	defer span.End()
+
+	credential, _, err := s.api.GetCredential(ctx, asset.ID, fleetdbapi.ServerCredentialTypeBMC)
+	if err != nil {
+		span.SetStatus(codes.Error, "GetCredential() failed")
+
+		return false, errors.Wrap(ErrInventoryQuery, "error querying BMC credentials: "+err.Error())
+	}
+
+	if credential.Username == "" || credential.Password == "" {
+		return false, errors.Wrap(ErrFleetDBObject, "BMC credential username or password field empty")
+	}
+
+	if credential.Username == asset.BmcUsername && credential.Password == asset.BmcPassword {
+		return false, nil
+	}
+
+	asset.BmcUsername = credential.Username
+	asset.BmcPassword = credential.Password
+
+	return true, nil
+}
+
+// BiosPassword returns the BIOS setup password to set on the server, and the password currently set,
+// the current password is empty when none was set by bioscfg.
+func (s *Store) BiosPassword(ctx context.Context, id uuid.UUID) (*model.BiosPassword, error) {
+	ctx, span := otel.Tracer(pkgName).Start(ctx, "fleetdb.BiosPassword")
+	defer span.End()
+
+	credential, _, err := s.api.GetCredential(ctx, id, ServerCredentialTypeBIOS)
+	if err != nil {
+		span.SetStatus(codes.Error, "GetCredential() failed")
+
+		return nil, errors.Wrap(ErrInventoryQuery, "error querying BIOS credentials: "+err.Error())
+	}
+
+	if credential.Password == "" {
+		return nil, errors.Wrap(ErrFleetDBObject, "BIOS password field empty")
+	}
+
+	password := &model.BiosPassword{New: credential.Password}
+
+	current, _, err := s.api.GetCredential(ctx, id, ServerCredentialTypeBIOSCurrent)
+	switch {
+	case isNotFound(err):
+	case err != nil:
+		span.SetStatus(codes.Error, "GetCredential() failed")
+
+		return nil, errors.Wrap(ErrInventoryQuery, "error querying current BIOS credentials: "+err.Error())
+	default:
+		password.Current = current.Password
+	}
+
+	return password, nil
+}
+
+// BiosPasswordRotated stores the BIOS setup password set on the server, and records the rotation time.
+func (s *Store) BiosPasswordRotated(ctx context.Context, id uuid.UUID, password string, rotatedAt time.Time) error {
+	ctx, span := otel.Tracer(pkgName).Start(ctx, "fleetdb.BiosPasswordRotated")
+	defer span.End()
+
+	_, err := s.api.SetCredential(ctx, id, ServerCredentialTypeBIOSCurrent, "", password)
+	if err != nil {
+		span.SetStatus(codes.Error, "SetCredential() failed")
+
+		return errors.Wrap(ErrServerServiceRegisterChanges, "error setting current BIOS credentials: "+err.Error())
+	}
+
+	data, err := json.Marshal(map[string]string{
+		biosPasswordRotatedAtAttributeKey: rotatedAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return errors.Wrap(ErrServerServiceAttrObject, err.Error())
+	}
+
+	_, err = s.api.UpdateAttributes(ctx, id, biosPasswordAttributeNS, data)
+	if isNotFound(err) {
+		_, err = s.api.CreateAttributes(ctx, id, fleetdbapi.Attributes{Namespace: biosPasswordAttributeNS, Data: data})
+	}
+
+	if err != nil {
+		span.SetStatus(codes.Error, "UpdateAttributes() failed")
+
+		return errors.Wrap(ErrServerServiceRegisterChanges, "error recording BIOS password rotation: "+err.Error())
+	}
+
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var serverErr fleetdbapi.ServerError
+	return errors.As(err, &serverErr) && serverErr.StatusCode == http.StatusNotFound
+}
+
 func toAsset(server *fleetdbapi.Server, credential *fleetdbapi.ServerCredential) (*model.Asset, error) {
 	if err := validateRequiredAttributes(server, credential); err != nil {
 		return nil, errors.Wrap(ErrFleetDBObject, err.Error())
@@ -79,16 +183,22 @@ func toAsset(server *fleetdbapi.Server, credential *fleetdbapi.ServerCredential)
 	}
 
 	asset := &model.Asset{
-		ID:     server.UUID,
-		Serial: serverAttributes[serverSerialAttributeKey],
-		Model:  serverAttributes[serverModelAttributeKey],
-		Vendor: serverAttributes[serverVendorAttributeKey],
+		ID:           server.UUID,
+		Serial:       serverAttributes[serverSerialAttributeKey],
+		Model:        serverAttributes[serverModelAttributeKey],
+		Vendor:       serverAttributes[serverVendorAttributeKey],
+		FacilityCode: server.FacilityCode,
 	}
 
 	if credential != nil {
 		asset.BmcUsername = credential.Username
 		asset.BmcPassword = credential.Password
-		asset.BmcAddress = net.ParseIP(serverAttributes[bmcIPAddressAttributeKey])
+		asset.BmcCertFingerprint = serverAttributes[bmcCertFingerprintAttributeKey]
+
+		asset.BmcAddress, err = model.ParseBMCAddress(serverAttributes[bmcIPAddressAttributeKey])
+		if err != nil {
+			return nil, errors.Wrap(ErrFleetDBObject, err.Error())
+		}
 	}
 
 	return asset, nil
@@ -119,7 +229,7 @@ func validateRequiredAttributes(server *fleetdbapi.Server, credential *fleetdbap
 }
 
 // serverAttributes parses the server service attribute data
-// and returns a map containing the bmc address, server serial, vendor, model attributes
+// and returns a map containing the bmc address, certificate fingerprint, server serial, vendor, model attributes
 func serverAttributes(attributes []fleetdbapi.Attributes) (map[string]string, error) {
 	// returned server attributes map
 	sAttributes := map[string]string{}
@@ -156,6 +266,9 @@ func serverAttributes(attributes []fleetdbapi.Attributes) (map[string]string, er
 		return nil, errors.New("expected BMC address attribute empty")
 	}
 
+	// set the optional pinned bmc certificate fingerprint
+	sAttributes[bmcCertFingerprintAttributeKey] = bmcData[bmcCertFingerprintAttributeKey]
+
 	// set server vendor, model attributes in the returned map
 	serverAttributes := []string{
 		serverSerialAttributeKey,
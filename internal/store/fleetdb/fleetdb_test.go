@@ -1,8 +1,6 @@
 package fleetdb
 
 import (
-	"net"
-
 	"testing"
 
 	"github.com/google/uuid"
@@ -111,7 +109,27 @@ func TestToAsset(t *testing.T) {
 				Attributes: []fleetdbapi.Attributes{
 					{
 						Namespace: fleetdbBMCInfoNS,
-						Data:      []byte(`{"address":"127.0.0.1"}`),
+						Data:      []byte(`{"address":"127.0.0.1","cert_fingerprint":"ab:cd"}`),
+					},
+				},
+			},
+			&fleetdbapi.ServerCredential{Username: "user", Password: "hunter2"},
+			&model.Asset{
+				ID:                 uuid.Nil,
+				BmcUsername:        "user",
+				BmcPassword:        "hunter2",
+				BmcAddress:         model.BMCAddress{Host: "127.0.0.1"},
+				BmcCertFingerprint: "ab:cd",
+			},
+			"",
+		},
+		{
+			"Hostname with port",
+			&fleetdbapi.Server{
+				Attributes: []fleetdbapi.Attributes{
+					{
+						Namespace: fleetdbBMCInfoNS,
+						Data:      []byte(`{"address":"bmc-01.example.com:8443"}`),
 					},
 				},
 			},
@@ -120,10 +138,43 @@ func TestToAsset(t *testing.T) {
 				ID:          uuid.Nil,
 				BmcUsername: "user",
 				BmcPassword: "hunter2",
-				BmcAddress:  net.ParseIP("127.0.0.1"),
+				BmcAddress:  model.BMCAddress{Host: "bmc-01.example.com", Port: "8443"},
 			},
 			"",
 		},
+		{
+			"IPv6 with zone and port",
+			&fleetdbapi.Server{
+				Attributes: []fleetdbapi.Attributes{
+					{
+						Namespace: fleetdbBMCInfoNS,
+						Data:      []byte(`{"address":"[fe80::1%eth0]:8443"}`),
+					},
+				},
+			},
+			&fleetdbapi.ServerCredential{Username: "user", Password: "hunter2"},
+			&model.Asset{
+				ID:          uuid.Nil,
+				BmcUsername: "user",
+				BmcPassword: "hunter2",
+				BmcAddress:  model.BMCAddress{Host: "fe80::1%eth0", Port: "8443"},
+			},
+			"",
+		},
+		{
+			"Invalid BMC address raises error",
+			&fleetdbapi.Server{
+				Attributes: []fleetdbapi.Attributes{
+					{
+						Namespace: fleetdbBMCInfoNS,
+						Data:      []byte(`{"address":"https://bmc_01/"}`),
+					},
+				},
+			},
+			&fleetdbapi.ServerCredential{Username: "user", Password: "hunter2"},
+			nil,
+			`"https://bmc_01/"`,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
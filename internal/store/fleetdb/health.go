@@ -0,0 +1,62 @@
+package fleetdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// the fleetdb readiness endpoint, served without authentication.
+const readinessPath = "/healthz/readiness"
+
+var (
+	ErrUnreachable  = errors.New("fleetdb unreachable")
+	ErrTokenInvalid = errors.New("fleetdb oidc token invalid")
+)
+
+// Ping checks fleetdb is ready to serve requests, and when authentication is enabled,
+// that a valid OIDC token is held, the token is refreshed by the token source when expired.
+func (s *Store) Ping(ctx context.Context) (string, error) {
+	detail := "reachable"
+
+	if s.tokens != nil {
+		token, err := s.tokens.Token()
+		if err != nil {
+			return "", errors.Wrap(ErrTokenInvalid, err.Error())
+		}
+
+		if !token.Valid() {
+			return "", ErrTokenInvalid
+		}
+
+		if !token.Expiry.IsZero() {
+			detail += fmt.Sprintf(", token expires in %s", time.Until(token.Expiry).Round(time.Second))
+		}
+	}
+
+	endpoint, err := url.JoinPath(s.config.URL, readinessPath)
+	if err != nil {
+		return "", errors.Wrap(ErrFleetDBConfig, err.Error())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return "", errors.Wrap(ErrUnreachable, err.Error())
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(ErrUnreachable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Wrap(ErrUnreachable, "readiness returned status "+resp.Status)
+	}
+
+	return detail, nil
+}
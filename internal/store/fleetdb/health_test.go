@@ -0,0 +1,74 @@
+package fleetdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+// staticTokens returns the given token, or error.
+type staticTokens struct {
+	token *oauth2.Token
+	err   error
+}
+
+func (s *staticTokens) Token() (*oauth2.Token, error) {
+	return s.token, s.err
+}
+
+func TestPing(t *testing.T) {
+	cases := []struct {
+		name       string
+		status     int
+		tokens     oauth2.TokenSource
+		down       bool
+		wantDetail string
+		wantErr    error
+	}{
+		{"ready", http.StatusOK, nil, false, "reachable", nil},
+		{
+			"ready with token",
+			http.StatusOK,
+			&staticTokens{token: &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}},
+			false,
+			"reachable, token expires in",
+			nil,
+		},
+		{"not ready", http.StatusServiceUnavailable, nil, false, "", ErrUnreachable},
+		{"unreachable", http.StatusOK, nil, true, "", ErrUnreachable},
+		{"token error", http.StatusOK, &staticTokens{err: errors.New("invalid_client")}, false, "", ErrTokenInvalid},
+		{"token expired", http.StatusOK, &staticTokens{token: &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(-time.Hour)}}, false, "", ErrTokenInvalid},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, readinessPath, r.URL.Path)
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			if tc.down {
+				srv.Close()
+			}
+
+			store := &Store{config: &Config{URL: srv.URL}, tokens: tc.tokens}
+
+			detail, err := store.Ping(context.Background())
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(detail, tc.wantDetail), detail)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/metal-toolbox/rivets/v2/events"
+	rkv "github.com/metal-toolbox/rivets/v2/events/pkg/kv"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+)
+
+const (
+	cancellationsBucket = "bioscfg-cancellations"
+	// conditions do not run longer than this, requests are kept for conditions picked up after the request.
+	cancellationsTTL = 24 * time.Hour
+)
+
+// Cancellations are the condition cancellation requests, keyed by condition ID in a NATS KV bucket,
+// the value is the optional reason given by the operator.
+type Cancellations struct {
+	kv nats.KeyValue
+}
+
+// NewCancellations binds the cancellations KV bucket, the bucket is created when missing.
+func NewCancellations(conn *nats.Conn, replicas int) (*Cancellations, error) {
+	bucket, err := rkv.CreateOrBindKVBucket(
+		events.NewJetstreamFromConn(conn),
+		cancellationsBucket,
+		rkv.WithTTL(cancellationsTTL),
+		rkv.WithReplicas(replicas),
+		rkv.WithDescription("bioscfg condition cancellation requests"),
+	)
+	if err != nil {
+		metrics.NATSError("bind-cancellations")
+		return nil, errors.Wrap(err, "bind kv bucket "+cancellationsBucket)
+	}
+
+	return &Cancellations{kv: bucket}, nil
+}
+
+// Request requests the cancellation of the condition.
+func (c *Cancellations) Request(conditionID, reason string) error {
+	if _, err := c.kv.PutString(conditionID, reason); err != nil {
+		metrics.NATSError("put-cancellation")
+		return errors.Wrap(err, "request cancellation of condition "+conditionID)
+	}
+
+	return nil
+}
+
+// Watch calls cancel with the reason once the cancellation of the condition is requested,
+// including a request made before the watch started, the watch stops when the context is done.
+func (c *Cancellations) Watch(ctx context.Context, conditionID string, cancel func(reason string)) error {
+	watcher, err := c.kv.Watch(conditionID, nats.Context(ctx))
+	if err != nil {
+		metrics.NATSError("watch-cancellation")
+		return errors.Wrap(err, "watch cancellation of condition "+conditionID)
+	}
+
+	go func() {
+		defer func() {
+			_ = watcher.Stop()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+
+				// a nil entry marks the end of the current values
+				if entry == nil || entry.Operation() != nats.KeyValuePut {
+					continue
+				}
+
+				cancel(string(entry.Value()))
+
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package kv
+
+import (
+	"github.com/metal-toolbox/rivets/v2/events"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+)
+
+// Connect opens a NATS connection, separate from the controller connection,
+// which is not exposed by the controller library.
+func Connect(cfg *events.NatsOptions) (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.Name(cfg.AppName),
+		nats.Timeout(cfg.ConnectTimeout),
+	}
+
+	switch {
+	case cfg.CredsFile != "":
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	case cfg.StreamUser != "":
+		opts = append(opts, nats.UserInfo(cfg.StreamUser, cfg.StreamPass))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		metrics.NATSError("connect")
+		return nil, errors.Wrap(err, "nats connect")
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,111 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/metal-toolbox/rivets/v2/events"
+	rkv "github.com/metal-toolbox/rivets/v2/events/pkg/kv"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/metal-toolbox/bioscfg/internal/metrics"
+)
+
+const (
+	resetsBucket = "bioscfg-bmc-resets"
+	// counts are kept past the day they are counted for, so a day is never missed around the UTC day change.
+	resetsTTL = 48 * time.Hour
+	// attempts to update a count when concurrent updates conflict.
+	resetsUpdateAttempts = 5
+)
+
+var (
+	errResetsUpdate = errors.New("error updating bmc reset count")
+)
+
+// BMCResets counts the BMC resets per asset per day in a NATS KV bucket,
+// so the count is shared by all controller replicas.
+type BMCResets struct {
+	kv  nats.KeyValue
+	now func() time.Time
+}
+
+// NewBMCResets binds the BMC resets KV bucket, the bucket is created when missing.
+func NewBMCResets(conn *nats.Conn, replicas int) (*BMCResets, error) {
+	bucket, err := rkv.CreateOrBindKVBucket(
+		events.NewJetstreamFromConn(conn),
+		resetsBucket,
+		rkv.WithTTL(resetsTTL),
+		rkv.WithReplicas(replicas),
+		rkv.WithDescription("BMC resets per asset per day"),
+	)
+	if err != nil {
+		metrics.NATSError("bind-bmc-resets")
+		return nil, errors.Wrap(err, "bind kv bucket "+resetsBucket)
+	}
+
+	return &BMCResets{kv: bucket, now: time.Now}, nil
+}
+
+// Allow increments the asset BMC reset count for the current UTC day,
+// false is returned without incrementing when the count reached maxPerDay.
+func (r *BMCResets) Allow(_ context.Context, assetID string, maxPerDay int) (bool, error) {
+	key := assetID + "." + r.now().UTC().Format("20060102")
+
+	for i := 0; i < resetsUpdateAttempts; i++ {
+		entry, err := r.kv.Get(key)
+		switch {
+		case errors.Is(err, nats.ErrKeyNotFound):
+			if maxPerDay < 1 {
+				return false, nil
+			}
+
+			_, err := r.kv.Create(key, []byte("1"))
+			if err == nil {
+				return true, nil
+			}
+
+			if !conflict(err) {
+				metrics.NATSError("update-bmc-resets")
+				return false, errors.Wrap(errResetsUpdate, err.Error())
+			}
+		case err != nil:
+			metrics.NATSError("get-bmc-resets")
+			return false, errors.Wrap(errResetsUpdate, err.Error())
+		default:
+			count, err := strconv.Atoi(string(entry.Value()))
+			if err != nil {
+				return false, errors.Wrap(errResetsUpdate, "invalid count: "+err.Error())
+			}
+
+			if count >= maxPerDay {
+				return false, nil
+			}
+
+			_, err = r.kv.Update(key, []byte(strconv.Itoa(count+1)), entry.Revision())
+			if err == nil {
+				return true, nil
+			}
+
+			if !conflict(err) {
+				metrics.NATSError("update-bmc-resets")
+				return false, errors.Wrap(errResetsUpdate, err.Error())
+			}
+		}
+	}
+
+	metrics.NATSError("update-bmc-resets")
+	return false, errors.Wrap(errResetsUpdate, "concurrent updates of "+key)
+}
+
+// conflict returns true when the count was created or updated by another replica since it was read.
+func conflict(err error) bool {
+	var apiErr *nats.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode == nats.JSErrCodeStreamWrongLastSequence
+	}
+
+	return errors.Is(err, nats.ErrKeyExists)
+}
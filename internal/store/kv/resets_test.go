@@ -0,0 +1,78 @@
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResetsKV fails the first updates of the count with the given error.
+type fakeResetsKV struct {
+	nats.KeyValue
+	updateErrs []error
+	updates    int
+}
+
+type fakeEntry struct {
+	nats.KeyValueEntry
+}
+
+func (fakeEntry) Value() []byte    { return []byte("1") }
+func (fakeEntry) Revision() uint64 { return 1 }
+
+func (f *fakeResetsKV) Get(string) (nats.KeyValueEntry, error) {
+	return fakeEntry{}, nil
+}
+
+func (f *fakeResetsKV) Update(string, []byte, uint64) (uint64, error) {
+	f.updates++
+	if f.updates <= len(f.updateErrs) {
+		return 0, f.updateErrs[f.updates-1]
+	}
+
+	return 2, nil
+}
+
+func TestBMCResetsAllow(t *testing.T) {
+	wrongSequence := &nats.APIError{ErrorCode: nats.JSErrCodeStreamWrongLastSequence, Code: 400}
+
+	cases := []struct {
+		name            string
+		updateErrs      []error
+		expectAllowed   bool
+		expectErr       bool
+		expectedUpdates int
+	}{
+		{"updated", nil, true, false, 1},
+		{"conflict is retried", []error{wrongSequence}, true, false, 2},
+		{"timeout is not retried", []error{nats.ErrTimeout}, false, true, 1},
+		{"permission error is not retried", []error{errors.New("nats: permissions violation")}, false, true, 1},
+		{
+			"concurrent updates",
+			[]error{wrongSequence, wrongSequence, wrongSequence, wrongSequence, wrongSequence},
+			false,
+			true,
+			resetsUpdateAttempts,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bucket := &fakeResetsKV{updateErrs: tc.updateErrs}
+			resets := &BMCResets{kv: bucket, now: time.Now}
+
+			allowed, err := resets.Allow(context.Background(), "asset", 3)
+			assert.Equal(t, tc.expectAllowed, allowed)
+			assert.Equal(t, tc.expectErr, err != nil, err)
+			assert.Equal(t, tc.expectedUpdates, bucket.updates)
+
+			if len(tc.updateErrs) > 0 && tc.updateErrs[0] != wrongSequence {
+				assert.Contains(t, err.Error(), tc.updateErrs[0].Error())
+			}
+		})
+	}
+}